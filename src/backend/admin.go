@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"recipe-finder/graph"
+	"time"
+)
+
+type reloadResponse struct {
+	Dataset  string `json:"dataset"`
+	Elements int    `json:"elements"`
+	MaxTier  int    `json:"maxTier"`
+}
+
+// reload parses and validates the recipe data of ds, then swaps it in and
+// purges the cache. Searches already running keep the graph they started
+// with. On error the current graph stays in use.
+func (s *server) reload(ds *dataset) (*graph.RecipeGraph, error) {
+	g, err := graph.Load(ds.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.swapMu.Lock()
+	ds.graph.Store(g)
+	// Dikosongkan semua, reload jarang terjadi jadi tidak perlu per dataset
+	s.results.Purge()
+	s.swapMu.Unlock()
+
+	log.Printf("Loaded %d elements from %s as %s", g.Len(), ds.path, ds.name)
+	return g, nil
+}
+
+// watchData reloads the recipe data of ds whenever its file changes. The
+// file is polled every interval, so it works on any file system.
+func (s *server) watchData(ds *dataset, interval time.Duration) {
+	last, _ := os.Stat(ds.path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		info, err := os.Stat(ds.path)
+		if err != nil {
+			continue
+		}
+		if last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size() {
+			continue
+		}
+		last = info
+
+		// File yang sedang ditulis bisa gagal di-parse, dicoba lagi saat berubah lagi
+		if _, err := s.reload(ds); err != nil {
+			log.Printf("Reloading %s failed, keeping the current data: %v", ds.path, err)
+		}
+	}
+}
+
+// handleReload reloads the recipe data of the dataset query parameter, or
+// of the default dataset, on demand. It is only registered when an admin
+// token is configured and requires it as a bearer token.
+func (s *server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+	token := []byte("Bearer " + s.adminToken)
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), token) != 1 {
+		writeError(w, newAPIError(http.StatusUnauthorized, codeUnauthorized, "missing or invalid admin token"))
+		return
+	}
+
+	name := r.URL.Query().Get("dataset")
+	if _, apiErr := s.graphFor(&name); apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	g, err := s.reload(s.datasets[name])
+	if err != nil {
+		apiErr := newAPIError(http.StatusUnprocessableEntity, codeInvalidData, "reload failed, the current data is still in use: %v", err)
+		var invalid *graph.ValidationError
+		if errors.As(err, &invalid) {
+			apiErr.Details = invalid.Problems
+		}
+		writeError(w, apiErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reloadResponse{Dataset: name, Elements: g.Len(), MaxTier: g.MaxTier()})
+}
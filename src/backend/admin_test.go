@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"recipe-finder/cache"
+	"testing"
+	"time"
+)
+
+const (
+	smallData = `{"Air": {"tier": 0}, "Water": {"tier": 0}, "Steam": {"tier": 1, "recipes": [["Air", "Water"]]}}`
+	largeData = `{"Air": {"tier": 0}, "Water": {"tier": 0}, "Steam": {"tier": 1, "recipes": [["Air", "Water"]]},
+		"Cloud": {"tier": 2, "recipes": [["Air", "Steam"]]}}`
+	// Cloud memakai elemen yang tidak ada dan Steam memakai elemen dari tier yang sama
+	invalidData = `{"Air": {"tier": 0}, "Water": {"tier": 0}, "Steam": {"tier": 1, "recipes": [["Air", "Mist"]]},
+		"Cloud": {"tier": 1, "recipes": [["Air", "Steam"]]}}`
+)
+
+// reloadRequest posts to /api/admin/reload with the given token and query
+func reloadRequest(s *server, token, query string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/api/admin/reload"+query, nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.handleReload(rec, r)
+	return rec
+}
+
+func TestHandleReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recipes.json")
+	os.WriteFile(path, []byte(smallData), 0o644)
+	s := newTestServer(t, "test="+path)
+	s.adminToken = "secret"
+	s.results = cache.New(8, time.Minute)
+
+	// Hasil yang tersimpan harus dibuang setelah reload
+	var resp RecipeResponse
+	req := RecipeRequest{Element: "Steam", Algorithm: "bfs", MaxRecipe: 1}
+	postRecipe(t, s, req, &resp)
+	if postRecipe(t, s, req, &resp); resp.Cache != cacheHit {
+		t.Fatalf("second search was not cached")
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		token    string
+		query    string
+		status   int
+		code     string
+		elements int
+	}{
+		{"no token", largeData, "", "", http.StatusUnauthorized, codeUnauthorized, 3},
+		{"wrong token", largeData, "guess", "", http.StatusUnauthorized, codeUnauthorized, 3},
+		{"unknown dataset", largeData, "secret", "?dataset=la9", http.StatusNotFound, codeUnknownDataset, 3},
+		{"reload", largeData, "secret", "", http.StatusOK, "", 4},
+		{"named", smallData, "secret", "?dataset=test", http.StatusOK, "", 3},
+		// Data yang tidak valid ditolak dan data lama tetap dipakai
+		{"invalid", invalidData, "secret", "", http.StatusUnprocessableEntity, codeInvalidData, 3},
+		{"broken json", `{"Air": `, "secret", "", http.StatusUnprocessableEntity, codeInvalidData, 3},
+	}
+
+	for _, tt := range tests {
+		os.WriteFile(path, []byte(tt.data), 0o644)
+		rec := reloadRequest(s, tt.token, tt.query)
+		if rec.Code != tt.status {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, rec.Code, tt.status, rec.Body)
+			continue
+		}
+		if tt.code != "" {
+			var body struct{ Error apiError }
+			json.NewDecoder(rec.Body).Decode(&body)
+			if body.Error.Code != tt.code {
+				t.Errorf("%s: error code %q, want %q", tt.name, body.Error.Code, tt.code)
+			}
+			if tt.name == "invalid" && len(body.Error.Details) != 2 {
+				t.Errorf("%s: details %v, want both problems", tt.name, body.Error.Details)
+			}
+		} else {
+			var body reloadResponse
+			json.NewDecoder(rec.Body).Decode(&body)
+			if body.Dataset != "test" || body.Elements != tt.elements {
+				t.Errorf("%s: response %+v, want %d elements of test", tt.name, body, tt.elements)
+			}
+		}
+		if got := s.datasets["test"].graph.Load().Len(); got != tt.elements {
+			t.Errorf("%s: graph has %d elements, want %d", tt.name, got, tt.elements)
+		}
+	}
+
+	if postRecipe(t, s, req, &resp); resp.Cache != cacheMiss {
+		t.Errorf("cached result survived the reload")
+	}
+
+	rec := httptest.NewRecorder()
+	s.handleReload(rec, httptest.NewRequest(http.MethodGet, "/api/admin/reload", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET answered %d", rec.Code)
+	}
+}
+
+func TestWatchData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recipes.json")
+	os.WriteFile(path, []byte(smallData), 0o644)
+	s := newTestServer(t, "test="+path)
+	go s.watchData(s.datasets["test"], 10*time.Millisecond)
+
+	waitFor := func(elements int) bool {
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if s.datasets["test"].graph.Load().Len() == elements {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Beri waktu watcher membaca keadaan awal file sebelum file diubah
+	time.Sleep(50 * time.Millisecond)
+	os.WriteFile(path, []byte(largeData), 0o644)
+	if !waitFor(4) {
+		t.Fatalf("changed data was not reloaded")
+	}
+	// File yang rusak dilewati, data terakhir yang valid tetap dipakai
+	os.WriteFile(path, []byte(invalidData), 0o644)
+	time.Sleep(50 * time.Millisecond)
+	if got := s.datasets["test"].graph.Load().Len(); got != 4 {
+		t.Errorf("invalid data replaced the graph, %d elements", got)
+	}
+	os.WriteFile(path, []byte(smallData), 0o644)
+	if !waitFor(3) {
+		t.Errorf("data fixed after an invalid write was not reloaded")
+	}
+}
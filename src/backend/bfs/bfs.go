@@ -1,152 +1,223 @@
 package bfs
 
 import (
-	"container/list"
-	"encoding/json"
-	"fmt"
+	"context"
 	"log"
-	"os"
+	"recipe-finder/graph"
+	"recipe-finder/model"
 	"runtime"
-	"sort"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// Global mutex for RecipeData
-var recipeDataMutex sync.RWMutex
-var RecipeData map[string]Recipe
-
-type Recipe struct {
-	Tier    int        `json:"tier"`
-	Recipes [][]string `json:"recipes"`
+// SearchBFS performs a breadth-first search to find recipes for the given element
+// The search stops early when ctx is cancelled, in which case the recipes
+// found so far are returned with Truncated set.
+func SearchBFS(ctx context.Context, g *graph.RecipeGraph, element string, maxRecipe int, opts model.Options) model.Result {
+	session := NewSession(g, element, opts)
+	defer session.Close()
+	return session.Next(ctx, maxRecipe)
 }
 
-func readRecipeJson() bool {
-	// Use mutex to protect RecipeData during initialization
-	recipeDataMutex.Lock()
-	defer recipeDataMutex.Unlock()
-
-	// Only read the file if RecipeData is nil
-	if RecipeData != nil {
-		return true
-	}
+// Session is a breadth-first search that can be continued. Every call to
+// Next picks up the frontier where the previous call stopped and returns
+// only recipes that were not returned before, so asking for more recipes
+// never repeats the work already done. The budget and Stats cover the
+// whole session. A Session is safe for concurrent use, calls to Next run
+// one at a time.
+type Session struct {
+	g       *graph.RecipeGraph
+	element string
+	opts    model.Options
+
+	mu       sync.Mutex
+	started  bool
+	frontier *frontier
+	metrics  model.Metrics
+	budget   *model.Budget
+	seen     map[string]bool
+	// extra holds recipes completed after the last Next had enough, they
+	// are returned first by the following call
+	extra     []map[string][]string
+	found     int
+	exhausted bool
+}
 
-	// Baca JSON
-	file, err := os.Open("./data/recipes_complete.json")
-	if err != nil {
-		log.Fatal(err)
-		return false
+// NewSession prepares a BFS for element. Nothing is searched until the
+// first call to Next. Close must be called once the session is no longer
+// needed to remove the frontier it spilled to disk.
+func NewSession(g *graph.RecipeGraph, element string, opts model.Options) *Session {
+	return &Session{
+		g:       g,
+		element: strings.TrimSpace(element),
+		opts:    opts,
+		budget:  model.NewBudget(opts),
+		seen:    make(map[string]bool),
 	}
-	defer file.Close()
+}
 
-	// Create a new map for RecipeData
-	tempRecipeData := make(map[string]Recipe)
+// Exhausted reports whether the session cannot find any more recipes,
+// either because the whole search space was explored or because a budget
+// ran out
+func (s *Session) Exhausted() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.exhausted && len(s.extra) == 0
+}
 
-	// Convert
-	if err := json.NewDecoder(file).Decode(&tempRecipeData); err != nil {
-		log.Fatal(err)
-		return false
+// Close releases the frontier of the session and gives its memory back to
+// the memory pool
+func (s *Session) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.frontier != nil {
+		s.frontier.close()
 	}
-
-	// Assign the loaded data to RecipeData
-	RecipeData = tempRecipeData
-	return true
+	s.budget.Close()
+	s.exhausted = true
 }
 
-// SearchBFS performs a breadth-first search to find recipes for the given element
-func SearchBFS(element string, maxRecipe int) ([]map[string][]string, float64, int) {
-	recipeDataMutex.Lock()
-	if RecipeData == nil {
-		recipeDataMutex.Unlock()
-		if !readRecipeJson() {
-			log.Println("Error reading JSON file")
-			return nil, 0, 0
-		}
+// Next continues the search until maxRecipe more recipes are found, the
+// search space is exhausted or ctx is cancelled. Duration covers this call
+// only, VisitedNode, Stats and Pruned the whole session so far.
+func (s *Session) Next(ctx context.Context, maxRecipe int) model.Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, opts, element := s.g, s.opts, s.element
+	if s.started {
+		log.Printf("Continuing BFS for element %s after %d recipes", element, s.found)
 	} else {
-		recipeDataMutex.Unlock()
+		log.Println("Starting BFS for element:", element)
 	}
-
-	element = strings.TrimSpace(element)
-	nodeCount := 0
-	log.Println("Starting BFS for element:", element)
 	startTime := time.Now()
 
-	recipeDataMutex.RLock()
-	_, ok := RecipeData[element]
-	recipeDataMutex.RUnlock()
+	var result []map[string][]string
+	// Resep yang sudah ditemukan panggilan sebelumnya dikembalikan lebih dulu
+	for len(s.extra) > 0 && len(result) < maxRecipe {
+		result = append(result, s.extra[0])
+		s.extra = s.extra[1:]
+		if opts.OnRecipe != nil {
+			opts.OnRecipe(result[len(result)-1])
+		}
+	}
 
-	if !ok {
-		duration := time.Since(startTime)
-		log.Println("Element not found in RecipeData")
-		log.Printf("BFS took %s", duration)
-		return nil, float64(duration.Seconds()), 0
+	if !s.started {
+		s.started = true
+		if !g.Has(element) {
+			duration := time.Since(startTime)
+			log.Println("Element not found in recipe graph")
+			log.Printf("BFS took %s", duration)
+			s.exhausted = true
+			return model.Result{Duration: duration.Seconds()}
+		}
+		if opts.IsLeaf(g, element) || len(g.Recipes(element)) == 0 {
+			duration := time.Since(startTime)
+			s.exhausted = true
+			s.found++
+			result = append(result, map[string][]string{element: {}})
+			if opts.OnRecipe != nil {
+				opts.OnRecipe(result[0])
+			}
+			log.Printf("BFS took %s", duration)
+			return model.Result{Recipes: result, Duration: duration.Seconds(), VisitedNode: 1}
+		}
+		s.frontier = newFrontier(opts, s.budget)
+		s.seed()
 	}
 
-	recipeDataMutex.RLock()
-	elementTier := RecipeData[element].Tier
-	recipeDataMutex.RUnlock()
+	truncated := false
+	if len(result) < maxRecipe && !s.exhausted {
+		result, truncated = s.run(ctx, result, maxRecipe)
+	}
+	s.found += len(result)
 
-	var result []map[string][]string
-	var resultMutex sync.Mutex
+	duration := time.Since(startTime)
+	log.Printf("BFS took %s", duration)
 
-	if elementTier == 0 || len(RecipeData[element].Recipes) == 0 {
-		duration := time.Since(startTime)
-		result = append(result, map[string][]string{element: {}})
-		log.Printf("BFS took %s", duration)
-		return result, float64(duration.Seconds()), 1
+	stats := s.metrics.Stats()
+	return model.Result{
+		Recipes:     result,
+		Duration:    duration.Seconds(),
+		VisitedNode: stats.VisitedNodes(),
+		Truncated:   truncated,
+		Budget:      s.budget.Hit(),
+		Pruned:      s.budget.Pruned(),
+		Stats:       stats,
 	}
+}
 
-	recipeQueue := list.New()
-	var queueMutex sync.Mutex
+// progress reports event to opts.OnProgress
+func (s *Session) progress(event model.Event) {
+	if s.opts.OnProgress != nil {
+		s.opts.OnProgress(event)
+	}
+}
 
-	recipeDataMutex.RLock()
-	recipes := RecipeData[element].Recipes
-	recipeDataMutex.RUnlock()
+// needs reports whether ingredient must still be expanded, that is when
+// it is no leaf and is neither chosen in current nor already pending
+func (s *Session) needs(current state, pending []int32, ingredient string) bool {
+	if s.opts.IsLeaf(s.g, ingredient) {
+		return false
+	}
+	id := int32(s.g.ID(ingredient))
+	return !current.has(id) && !slices.Contains(pending, id)
+}
 
-	nodeCount++
-	for _, recipe := range recipes {
-		nodeCount += 2
+// seed puts one state for every usable recipe of the target on the frontier
+func (s *Session) seed() {
+	g, element := s.g, s.element
+	elementTier := g.Tier(element)
+	recipes := s.opts.Recipes(g, element)
 
-		recipeDataMutex.RLock()
-		recipe0Tier := RecipeData[recipe[0]].Tier
-		recipe1Tier := RecipeData[recipe[1]].Tier
-		elementTier := RecipeData[element].Tier
-		recipeDataMutex.RUnlock()
+	s.metrics.Expand()
+	s.budget.Visit(1)
+	for i, recipe := range recipes {
+		s.metrics.Consider()
+		s.budget.Visit(2)
 
-		if recipe0Tier < elementTier && recipe1Tier < elementTier {
-			currentState := make(map[string][]string)
-			currentState[element] = recipe
+		recipe0Tier := g.Tier(recipe[0])
+		recipe1Tier := g.Tier(recipe[1])
 
-			state := map[string]interface{}{
-				"recipeMap": currentState,
-				"queue":     list.New(),
+		if recipe0Tier < elementTier && recipe1Tier < elementTier {
+			var pending []int32
+			for _, ingredient := range recipe {
+				if s.needs(state{}, pending, ingredient) {
+					pending = append(pending, int32(g.ID(ingredient)))
+				}
 			}
 
-			fmt.Printf("Adding initial state: %s", element)
-			fmt.Printf("Recipe: %s", recipe[0])
-			fmt.Printf("+ %s", recipe[1])
-			fmt.Println()
-
-			recipeDataMutex.RLock()
-			if RecipeData[recipe[0]].Tier > 0 && recipe0Tier < elementTier {
-				state["queue"].(*list.List).PushBack(recipe[0])
-				fmt.Printf("Adding to queue: %s\n", recipe[0])
-			}
-			if RecipeData[recipe[1]].Tier > 0 && recipe[0] != recipe[1] && recipe1Tier < elementTier {
-				state["queue"].(*list.List).PushBack(recipe[1])
-				fmt.Printf("Adding to queue: %s\n", recipe[1])
+			if !s.frontier.push(state{}.with(int32(g.ID(element)), int32(i), pending)) {
+				continue
 			}
-			recipeDataMutex.RUnlock()
-
-			recipeQueue.PushBack(state)
+			s.metrics.Generate(s.frontier.Len())
+			s.progress(model.Event{Type: model.EventEnqueue, Element: element, Recipe: recipe, Frontier: s.frontier.Len()})
 		}
 	}
+}
+
+// run expands the frontier with a pool of workers until result holds
+// maxRecipe recipes. A worker always finishes the state it took, so the
+// frontier left behind is complete and a later call can go on from it.
+// Recipes completed after result is full are kept in s.extra. It reports
+// whether the search stopped before it had enough recipes and before the
+// search space was exhausted.
+func (s *Session) run(ctx context.Context, result []map[string][]string, maxRecipe int) ([]map[string][]string, bool) {
+	g, opts, metrics, budget := s.g, s.opts, &s.metrics, s.budget
+	recipeQueue := s.frontier
+	var queueMutex sync.Mutex
 
 	done := make(chan bool)
 	resultChan := make(chan map[string][]string)
 
 	numWorkers := max(runtime.NumCPU()/2, 1)
+	if opts.Deterministic {
+		// Satu worker memproses queue sesuai urutan FIFO sehingga hasilnya selalu sama
+		numWorkers = 1
+	}
 	var wg sync.WaitGroup
 
 	var doneMutex sync.Mutex
@@ -161,6 +232,26 @@ func SearchBFS(element string, maxRecipe int) ([]map[string][]string, float64, i
 		}
 	}
 
+	// Setelah ada state yang dibuang karena budget memori, pencarian dianggap
+	// macet jika satu putaran penuh frontier tidak menemukan resep baru
+	var found atomic.Int64
+	passLeft, passFound, stalled := 0, int64(0), false
+	startPass := func() {
+		if passLeft == 0 {
+			passLeft, passFound = max(recipeQueue.Len(), 1), found.Load()
+		}
+	}
+	endPass := func() bool {
+		if passLeft == 0 {
+			return false
+		}
+		passLeft--
+		if passLeft == 0 && found.Load() == passFound {
+			stalled = true
+		}
+		return stalled
+	}
+
 	var activeWorkers int
 	var activeWorkersMutex sync.Mutex
 
@@ -181,55 +272,31 @@ func SearchBFS(element string, maxRecipe int) ([]map[string][]string, float64, i
 		defer activeWorkersMutex.Unlock()
 		return activeWorkers
 	}
-	
-	// Channel to coordinate queue management
-	queueFullSignal := make(chan bool, 1)
-	
-	seenRecipes := make(map[string]bool)
-	var seenMutex sync.Mutex
-	
+
+	collectorDone := make(chan struct{})
 	go func() {
+		defer close(collectorDone)
 		for r := range resultChan {
-			serialized := createRecipeFingerprint(r)
-
-			seenMutex.Lock()
-			if !seenRecipes[serialized] {
-				seenRecipes[serialized] = true
-				resultMutex.Lock()
-				result = append(result, r)
-				fmt.Printf("Found new recipe: %s\n", serialized)
-				fmt.Println()
-				if len(result) >= maxRecipe {
-					safeCloseDone()
-				}
-				resultMutex.Unlock()
+			serialized := model.Fingerprint(r)
+			if s.seen[serialized] {
+				metrics.Duplicate()
+				continue
+			}
+			s.seen[serialized] = true
+			found.Add(1)
+			if len(result) >= maxRecipe {
+				// Worker yang sudah terlanjur mengirim sebelum done ditutup,
+				// disimpan untuk panggilan Next berikutnya
+				s.extra = append(s.extra, r)
+				continue
+			}
+			result = append(result, r)
+			if len(result) >= maxRecipe {
+				safeCloseDone()
 			}
-			seenMutex.Unlock()
-		}
-	}()
 
-	// Queue monitoring goroutine
-	go func() {
-		for {
-			select {
-			case <-done:
-				return
-			default:
-				queueMutex.Lock()
-				queueSize := recipeQueue.Len()
-				queueMutex.Unlock()
-				
-				// If queue was full but now has some space
-				if queueSize < 90000 {
-					select {
-					case queueFullSignal <- false:
-						// Signal sent that queue has space
-					default:
-						// Channel already has signal or no one is waiting
-					}
-				}
-				
-				time.Sleep(100 * time.Millisecond)
+			if opts.OnRecipe != nil {
+				opts.OnRecipe(r)
 			}
 		}
 	}()
@@ -258,265 +325,127 @@ func SearchBFS(element string, maxRecipe int) ([]map[string][]string, float64, i
 					continue
 				}
 
+				// Ditandai aktif sebelum queue dilepas agar worker lain tidak mengira pencarian selesai
 				increaseActive()
-				currentStateElement := recipeQueue.Front()
-				recipeQueue.Remove(currentStateElement)
+				currentState, _ := recipeQueue.pop()
+				frontier := recipeQueue.Len()
+				stop := endPass()
 				queueMutex.Unlock()
+				if stop {
+					safeCloseDone()
+				}
 
-				currentState := currentStateElement.Value.(map[string]interface{})
-				currentRecipeMap := currentState["recipeMap"].(map[string][]string)
-				currentQueue := currentState["queue"].(*list.List)
-
-				if currentQueue.Len() == 0 {
-					recipeDone := make(map[string][]string)
-					for key, value := range currentRecipeMap {
-						recipeDone[key] = value
-					}
+				// Lewati elemen pending yang resepnya sudah dipilih lewat cabang lain
+				pending := currentState.pending
+				for len(pending) > 0 && currentState.has(pending[0]) {
+					pending = pending[1:]
+				}
 
-					select {
-					case <-done:
-						decreaseActive()
-						return
-					case resultChan <- recipeDone:
-					}
+				if len(pending) == 0 {
+					// Collector selalu menerima, jadi resep tidak hilang saat done ditutup
+					resultChan <- currentState.recipeMap(g, opts)
+					release(budget, currentState.recipes)
 					decreaseActive()
 					continue
 				}
 
-				nextElement := currentQueue.Front()
-				currentQueue.Remove(nextElement)
-				for _, ok := currentRecipeMap[nextElement.Value.(string)]; ok; {
-					if currentQueue.Len() == 0 {
-						break
-					}
-					nextElement = currentQueue.Front()
-					currentQueue.Remove(currentQueue.Front())
-					if nextElement == nil {
-						break
-					}
-					_, ok = currentRecipeMap[nextElement.Value.(string)]
-				}
-				elementToExpand := nextElement.Value.(string)
-				fmt.Printf("Expanding: %s\n", elementToExpand)
+				expandID, rest := pending[0], pending[1:]
+				elementToExpand := g.Name(int(expandID))
+				metrics.Expand()
+				s.progress(model.Event{Type: model.EventExpand, Element: elementToExpand, Frontier: frontier})
 
-				recipeDataMutex.RLock()
-				elementRecipes := RecipeData[elementToExpand].Recipes
-				recipeDataMutex.RUnlock()
+				elementRecipes := opts.Recipes(g, elementToExpand)
+				elementTier := g.Tier(elementToExpand)
 
-				for _, recipe := range elementRecipes {
-					nodeCount += 2
-
-					select {
-					case <-done:
-						decreaseActive()
-						return
-					default:
+				// State diexpand sampai habis walaupun done sudah ditutup,
+				// supaya frontier tetap lengkap untuk panggilan berikutnya
+				for i, recipe := range elementRecipes {
+					metrics.Consider()
+					if !budget.Visit(2) {
+						safeCloseDone()
+						break
 					}
 
-					recipeDataMutex.RLock()
-					recipe0Tier := RecipeData[recipe[0]].Tier
-					recipe1Tier := RecipeData[recipe[1]].Tier
-					elementTier := RecipeData[elementToExpand].Tier
-					recipeDataMutex.RUnlock()
+					recipe0Tier := g.Tier(recipe[0])
+					recipe1Tier := g.Tier(recipe[1])
 
 					if recipe0Tier < elementTier && recipe1Tier < elementTier {
-						newRecipeMap := make(map[string][]string)
-						for key, value := range currentRecipeMap {
-							newRecipeMap[key] = value
-						}
-						newRecipeMap[elementToExpand] = recipe
-
-						fmt.Printf("Adding new recipe: %s\n", elementToExpand)
-						fmt.Printf("Recipe: %s", recipe[0])
-						fmt.Printf("+ %s", recipe[1])
-						fmt.Println()
-
-						recipe0Found := false
-						recipe1Found := false
-						newQueue := list.New()
-						for el := currentQueue.Front(); el != nil; el = el.Next() {
-							newQueue.PushBack(el.Value)
-							if el.Value.(string) == recipe[0] {
-								recipe0Found = true
-							}
-							if el.Value.(string) == recipe[1] {
-								recipe1Found = true
+						newPending := make([]int32, len(rest), len(rest)+2)
+						copy(newPending, rest)
+						next := currentState.with(expandID, int32(i), nil)
+						for _, ingredient := range recipe {
+							if s.needs(next, newPending, ingredient) {
+								newPending = append(newPending, int32(g.ID(ingredient)))
 							}
 						}
+						next.pending = newPending
 
-						recipeDataMutex.RLock()
-						if _, ok := newRecipeMap[recipe[0]]; !recipe0Found && !ok && recipe0Tier > 0 {
-							newQueue.PushBack(recipe[0])
-							fmt.Printf("Adding to queue: %s\n", recipe[0])
+						// State hanya dibuang jika budget memori habis
+						queueMutex.Lock()
+						pushed := recipeQueue.push(next)
+						if !pushed {
+							startPass()
 						}
-						if _, ok := newRecipeMap[recipe[1]]; !recipe1Found && recipe[0] != recipe[1] && !ok && recipe1Tier > 0 {
-							newQueue.PushBack(recipe[1])
-							fmt.Printf("Adding to queue: %s\n", recipe[1])
+						frontier := recipeQueue.Len()
+						queueMutex.Unlock()
+						if !pushed {
+							continue
 						}
-						recipeDataMutex.RUnlock()
+						metrics.Generate(frontier)
 
-						newState := map[string]interface{}{
-							"recipeMap": newRecipeMap,
-							"queue":     newQueue,
-						}
-						for item := newQueue.Front(); item != nil; item = item.Next() {
-							fmt.Printf("Queue item: %s\n", item.Value)
-						}
-
-						fmt.Printf("Adding new recipe: %s\n", elementToExpand)
-						
-						// Improved queue management with backoff strategy
-						var addedToQueue bool
-						backoffTime := 1 * time.Millisecond
-						maxBackoff := 500 * time.Millisecond
-						attempts := 0
-						maxAttempts := 10 // Set a reasonable limit for attempts
-						
-						for attempts < maxAttempts {
-							select {
-							case <-done:
-								decreaseActive()
-								return
-							default:
-							}
-							
-							queueMutex.Lock()
-							queueSize := recipeQueue.Len()
-							queueFull := queueSize >= 100000
-							queueMutex.Unlock()
-							
-							resultMutex.Lock()
-							allRecipes := len(result) >= maxRecipe
-							resultMutex.Unlock()
-							
-							if allRecipes {
-								// We've reached our goal, no need to add more
-								break
-							}
-							
-							if !queueFull {
-								queueMutex.Lock()
-								recipeQueue.PushBack(newState)
-								queueMutex.Unlock()
-								
-								fmt.Printf("Added new state: %s\n", elementToExpand)
-								addedToQueue = true
-								break
-							}
-							
-							// If the queue is full, wait for the queue to have space
-							attempts++
-							
-							if attempts >= 3 {
-								// After a few attempts, check if we should yield to let other workers process the queue
-								select {
-								case <-queueFullSignal:
-									// Queue has space now
-									continue
-								case <-time.After(backoffTime):
-									// Apply exponential backoff with jitter
-									if backoffTime*2 > maxBackoff {
-										backoffTime = maxBackoff
-									} else {
-										backoffTime = backoffTime * 2
-									}
-								case <-done:
-									decreaseActive()
-									return
-								}
-							} else {
-								time.Sleep(backoffTime)
-							}
-						}
-						
-						// If we couldn't add to queue after max attempts, handle the situation
-						if !addedToQueue && attempts >= maxAttempts {
-							fmt.Printf("Failed to add state for %s after %d attempts, queue may be deadlocked\n", 
-								elementToExpand, maxAttempts)
-							
-							// Handle the situation - either discard this state or
-							// If there are workers waiting but no progress is being made,
-							// we might want to force some cleanup or restart
-							
-							// Check if the search is still making progress
-							select {
-							case <-done:
-								decreaseActive()
-								return
-							default:
-								// Check if we should terminate due to lack of progress
-								resultMutex.Lock()
-								resultCount := len(result)
-								resultMutex.Unlock()
-								
-								if resultCount > 0 {
-									// If we have some results already, it's okay to discard this state
-									fmt.Printf("Discarding this state for %s after max attempts, we have %d results\n", 
-										elementToExpand, resultCount)
-								}
-							}
-						}
+						s.progress(model.Event{Type: model.EventEnqueue, Element: elementToExpand, Recipe: recipe, Frontier: frontier})
 					}
 				}
+				// Binding state ini tetap hidup selama masih dipakai state hasil expand
+				release(budget, currentState.recipes)
 				decreaseActive()
 			}
 		}()
 	}
 
+	// Stop every worker as soon as the caller gives up
+	cancelled := false
+	go func() {
+		select {
+		case <-ctx.Done():
+			doneMutex.Lock()
+			cancelled = !isDoneClosed
+			doneMutex.Unlock()
+			safeCloseDone()
+		case <-done:
+		}
+	}()
+
 	wg.Wait()
 	safeCloseDone()
 	close(resultChan)
+	<-collectorDone
 
-	duration := time.Since(startTime)
-	log.Printf("BFS took %s", duration)
-
-	// fmt.Println(result)
-	return result, float64(duration.Seconds()), nodeCount
-}
-
-// Helper functions for limiting workers
-func min(a, b int) int {
-	if a < b {
-		return a
+	if budget.OutOfNodes() || stalled {
+		// State yang tersisa tidak akan pernah diexpand
+		budget.Prune(recipeQueue.Len())
 	}
-	return b
-}
-
-func max(a, b int) int {
-	if a > b {
-		return a
+	if stalled {
+		log.Printf("BFS stopped, a whole pass through the frontier found no recipe while states were pruned")
 	}
-	return b
-}
-
-// Creates a canonical string representation of a recipe for deduplication
-func createRecipeFingerprint(recipe map[string][]string) string {
-	// Sort elements first to ensure consistent ordering
-	elements := make([]string, 0, len(recipe))
-	for element := range recipe {
-		elements = append(elements, element)
+	if budget.Hit() != "" {
+		log.Printf("BFS ran out of its %s budget, %d states pruned", budget.Hit(), budget.Pruned())
 	}
-
-	sort.Strings(elements)
-
-	// Build fingerprint
-	var fingerprint strings.Builder
-	for _, element := range elements {
-		fingerprint.WriteString(element)
-		fingerprint.WriteString(":[")
-
-		components := recipe[element]
-		// Also sort components for consistency
-		sort.Strings(components)
-
-		for i, component := range components {
-			if i > 0 {
-				fingerprint.WriteString(",")
-			}
-			fingerprint.WriteString(component)
-		}
-		fingerprint.WriteString("]")
+	// Frontier kosong berarti semua resep sudah ditemukan
+	if budget.OutOfNodes() || stalled || recipeQueue.Len() == 0 {
+		s.exhausted = true
+		recipeQueue.close()
 	}
 
-	return fingerprint.String()
-}
\ No newline at end of file
+	doneMutex.Lock()
+	truncated := (cancelled || budget.Hit() != "") && len(result) < maxRecipe
+	doneMutex.Unlock()
+	if cancelled && truncated {
+		log.Printf("BFS stopped early: %v", ctx.Err())
+	}
+	if recipeQueue.lost > 0 && len(result) < maxRecipe {
+		// Sebagian ruang pencarian hilang bersama state yang gagal dibaca
+		truncated = true
+	}
+	return result, truncated
+}
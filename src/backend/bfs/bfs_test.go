@@ -0,0 +1,81 @@
+package bfs
+
+import (
+	"context"
+	"fmt"
+	"recipe-finder/graph"
+	"recipe-finder/model"
+	"testing"
+)
+
+// chainGraph makes Link<n> from Link<n-1> and any base element, so every
+// element has four recipes and the target can be made in 4^n ways
+func chainGraph(n int) *graph.RecipeGraph {
+	data := map[string]graph.Recipe{
+		"Air":   {Tier: 0},
+		"Earth": {Tier: 0},
+		"Fire":  {Tier: 0},
+		"Water": {Tier: 0},
+		"Link0": {Tier: 1, Recipes: [][]string{{"Air", "Earth"}}},
+	}
+	for i := 1; i <= n; i++ {
+		prev := fmt.Sprintf("Link%d", i-1)
+		var recipes [][]string
+		for _, base := range []string{"Air", "Earth", "Fire", "Water"} {
+			recipes = append(recipes, []string{prev, base})
+		}
+		data[fmt.Sprintf("Link%d", i)] = graph.Recipe{Tier: i + 1, Recipes: recipes}
+	}
+	return graph.New(data)
+}
+
+func TestSearchBFSStalled(t *testing.T) {
+	g := chainGraph(6)
+	opts := model.Options{Deterministic: true}
+	if result := SearchBFS(context.Background(), g, "Link6", 1, opts); len(result.Recipes) != 1 || result.Truncated {
+		t.Fatalf("search without a budget found %d recipes, truncated %v", len(result.Recipes), result.Truncated)
+	}
+
+	// Budget hanya cukup untuk beberapa state. Tanpa batas ini BFS masih bisa
+	// menemukan resep lewat queue yang terus dipangkas, tetapi sangat lambat
+	// untuk target yang dalam, jadi BFS berhenti setelah satu putaran tanpa resep baru
+	opts.MaxMemory = 1200
+	session := NewSession(g, "Link6", opts)
+	defer session.Close()
+	result := session.Next(context.Background(), 1)
+	if len(result.Recipes) != 0 || !result.Truncated || result.Budget != model.BudgetMemory {
+		t.Fatalf("found %d recipes, truncated %v, budget %q; want a truncated search out of memory", len(result.Recipes), result.Truncated, result.Budget)
+	}
+	if !session.Exhausted() {
+		t.Errorf("stalled session is not exhausted")
+	}
+	// Berhenti setelah satu putaran, bukan setelah semua 4^6 cara dicoba
+	if result.Stats.Expanded > 100 {
+		t.Errorf("expanded %d states before stopping", result.Stats.Expanded)
+	}
+}
+
+func TestSearchBFSMemoryPool(t *testing.T) {
+	g := chainGraph(4)
+	pool := model.NewMemoryPool(1 << 20)
+	opts := model.Options{Deterministic: true, MemoryPool: pool}
+
+	result := SearchBFS(context.Background(), g, "Link4", 10, opts)
+	if len(result.Recipes) != 10 || result.Budget != "" {
+		t.Fatalf("found %d recipes, budget %q; want 10 within the pool", len(result.Recipes), result.Budget)
+	}
+	if used := pool.Used(); used != 0 {
+		t.Errorf("pool holds %d bytes after the search", used)
+	}
+
+	// Sesi yang masih terbuka menahan memorinya sampai ditutup
+	session := NewSession(g, "Link4", opts)
+	session.Next(context.Background(), 3)
+	if pool.Used() == 0 {
+		t.Errorf("open session holds no memory of the pool")
+	}
+	session.Close()
+	if used := pool.Used(); used != 0 {
+		t.Errorf("pool holds %d bytes after the session was closed", used)
+	}
+}
@@ -0,0 +1,340 @@
+package bfs
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"recipe-finder/graph"
+	"recipe-finder/model"
+	"sync/atomic"
+)
+
+// DefaultSpillAfter is the number of frontier states kept in memory before
+// BFS starts spilling, when spilling is enabled
+const DefaultSpillAfter = 1 << 18
+
+// chunkSize is the number of states stored, spilled and loaded together
+const chunkSize = 4096
+
+// stateOverhead estimates the bytes of a state without its pending ids,
+// which is the state itself and its slot in a chunk. bindingSize is one
+// binding, it is counted from the moment it is created until the last
+// state or binding referring to it is released.
+const (
+	stateOverhead = 32
+	bindingSize   = 24
+)
+
+// state is one partial recipe waiting on the frontier. The chosen recipes
+// form a persistent list shared with the state it was expanded from, so a
+// new state only costs one binding and its pending ids.
+type state struct {
+	recipes *binding
+	// pending holds the ids of the elements that still need a recipe, in
+	// the order they are expanded
+	pending []int32
+}
+
+// binding records the recipe chosen for one element of a state
+type binding struct {
+	parent  *binding
+	element int32
+	// recipe is the index into opts.Recipes of element
+	recipe int32
+	// refs counts the states and child bindings that refer to this one
+	refs atomic.Int32
+}
+
+// size estimates the memory held by the state apart from its bindings,
+// which are counted separately since they are shared
+func (s state) size() int64 {
+	return stateOverhead + 4*int64(cap(s.pending))
+}
+
+// depth returns the number of bindings of the state
+func (s state) depth() int {
+	n := 0
+	for b := s.recipes; b != nil; b = b.parent {
+		n++
+	}
+	return n
+}
+
+// has reports whether the state already chose a recipe for element
+func (s state) has(element int32) bool {
+	for b := s.recipes; b != nil; b = b.parent {
+		if b.element == element {
+			return true
+		}
+	}
+	return false
+}
+
+// with returns a new state that also uses recipe for element. pending
+// belongs to the new state and must not be shared. The new state holds a
+// reference to its binding until it is released.
+func (s state) with(element, recipe int32, pending []int32) state {
+	b := &binding{parent: s.recipes, element: element, recipe: recipe}
+	b.refs.Store(1)
+	if s.recipes != nil {
+		s.recipes.refs.Add(1)
+	}
+	return state{recipes: b, pending: pending}
+}
+
+// recipeMap expands the state into the map returned to callers
+func (s state) recipeMap(g *graph.RecipeGraph, opts model.Options) map[string][]string {
+	recipeMap := make(map[string][]string)
+	for b := s.recipes; b != nil; b = b.parent {
+		name := g.Name(int(b.element))
+		recipeMap[name] = opts.Recipes(g, name)[b.recipe]
+	}
+	return recipeMap
+}
+
+// chunk is a block of consecutive frontier states. A spilled chunk lives
+// in file until it reaches the front of the frontier.
+type chunk struct {
+	states []state
+	head   int
+	file   string
+	// spilled is the number of states written to file
+	spilled int
+}
+
+// frontier is the FIFO queue of states waiting to be expanded. States are
+// kept in fixed size chunks so popping releases memory as it goes. When
+// spillDir is set, every full chunk beyond spillAfter states is written
+// to disk and read back once it is next in line, so the frontier can
+// grow past memory without dropping states. The states held in memory are
+// counted against the memory budget. It is not safe for concurrent use.
+type frontier struct {
+	chunks     []*chunk
+	length     int
+	inMemory   int
+	spillDir   string
+	spillAfter int
+	budget     *model.Budget
+	// lost counts states of spilled chunks that could not be read back
+	lost int
+}
+
+func newFrontier(opts model.Options, budget *model.Budget) *frontier {
+	f := &frontier{spillDir: opts.SpillDir, spillAfter: opts.SpillAfter, budget: budget}
+	if f.spillAfter <= 0 {
+		f.spillAfter = DefaultSpillAfter
+	}
+	return f
+}
+
+// Len returns the number of states waiting, including spilled ones
+func (f *frontier) Len() int {
+	return f.length
+}
+
+// push adds a state made by with to the back, it reports false when the
+// state is pruned because the memory budget is used up
+func (f *frontier) push(s state) bool {
+	if !f.budget.Reserve(s.size() + bindingSize) {
+		f.budget.Prune(1)
+		// Binding state ini tidak pernah dihitung, hanya referensi ke parent-nya yang dilepas
+		release(f.budget, s.recipes.parent)
+		return false
+	}
+	last := len(f.chunks) - 1
+	if last < 0 || len(f.chunks[last].states) == chunkSize {
+		// Chunk terakhir penuh, di-spill jika bukan chunk yang sedang dibaca
+		if last > 0 && f.spillDir != "" && f.inMemory >= f.spillAfter {
+			f.spill(f.chunks[last])
+		}
+		f.chunks = append(f.chunks, &chunk{states: make([]state, 0, chunkSize)})
+		last++
+	}
+	f.chunks[last].states = append(f.chunks[last].states, s)
+	f.length++
+	f.inMemory++
+	return true
+}
+
+// pop removes the oldest state, ok is false when the frontier is empty.
+// The caller releases the state once it is done with it.
+func (f *frontier) pop() (state, bool) {
+	for len(f.chunks) > 0 {
+		front := f.chunks[0]
+		if front.file != "" {
+			f.load(front)
+		}
+		if front.head < len(front.states) {
+			s := front.states[front.head]
+			front.states[front.head] = state{}
+			front.head++
+			f.length--
+			f.inMemory--
+			f.budget.Track(-s.size())
+			return s, true
+		}
+		f.chunks[0] = nil
+		f.chunks = f.chunks[1:]
+	}
+	return state{}, false
+}
+
+// spill writes a full chunk to a temporary file and frees its states. On
+// failure the chunk stays in memory and spilling is turned off.
+func (f *frontier) spill(c *chunk) {
+	file, err := os.CreateTemp(f.spillDir, "bfs-frontier-*")
+	if err != nil {
+		log.Printf("BFS cannot spill its frontier, keeping it in memory: %v", err)
+		f.spillDir = ""
+		return
+	}
+	w := bufio.NewWriter(file)
+	buf := make([]byte, 0, 64)
+	var bytes int64
+	for _, s := range c.states[c.head:] {
+		buf = encodeState(buf[:0], s)
+		w.Write(buf)
+		bytes += s.size()
+	}
+	// State di memori dilepas setelah file berhasil ditulis
+	err = w.Flush()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		log.Printf("BFS cannot spill its frontier, keeping it in memory: %v", err)
+		f.spillDir = ""
+		return
+	}
+
+	c.spilled = len(c.states) - c.head
+	f.inMemory -= c.spilled
+	f.budget.Track(-bytes)
+	for _, s := range c.states[c.head:] {
+		release(f.budget, s.recipes)
+	}
+	c.states, c.head, c.file = nil, 0, file.Name()
+}
+
+// load reads a spilled chunk back into memory and removes its file. States
+// that cannot be read are counted in lost.
+func (f *frontier) load(c *chunk) {
+	states, err := readChunk(c.file)
+	os.Remove(c.file)
+	c.file = ""
+	if missing := c.spilled - len(states); err != nil || missing > 0 {
+		log.Printf("BFS lost %d spilled states: %v", missing, err)
+		f.lost += missing
+		f.length -= missing
+	}
+	c.states, c.head = states, 0
+	f.inMemory += len(states)
+	// Binding hasil decode tidak lagi dibagi, jadi dihitung per state
+	for _, s := range states {
+		f.budget.Track(s.size() + bindingSize*int64(s.depth()))
+	}
+}
+
+// release drops one reference to b and gives the memory of every binding
+// that is no longer referenced back to the budget. It is safe to call from
+// several workers at once.
+func release(budget *model.Budget, b *binding) {
+	for ; b != nil && b.refs.Add(-1) == 0; b = b.parent {
+		budget.Track(-bindingSize)
+	}
+}
+
+// close removes the files of every chunk that is still spilled
+func (f *frontier) close() {
+	for _, c := range f.chunks {
+		if c.file != "" {
+			os.Remove(c.file)
+		}
+	}
+	f.chunks = nil
+}
+
+// encodeState appends s as uvarints: the number of bindings followed by
+// their element and recipe, then the number of pending ids and the ids.
+// Bindings shared with other states are written out in full, so a spilled
+// state does not depend on anything left in memory.
+func encodeState(buf []byte, s state) []byte {
+	buf = binary.AppendUvarint(buf, uint64(s.depth()))
+	for b := s.recipes; b != nil; b = b.parent {
+		buf = binary.AppendUvarint(buf, uint64(b.element))
+		buf = binary.AppendUvarint(buf, uint64(b.recipe))
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(s.pending)))
+	for _, id := range s.pending {
+		buf = binary.AppendUvarint(buf, uint64(id))
+	}
+	return buf
+}
+
+func decodeState(r *bufio.Reader) (state, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return state{}, err
+	}
+	// Dibaca dari binding terbaru, disusun ulang dari yang paling lama
+	bindings := make([]binding, n)
+	for i := range bindings {
+		element, err := binary.ReadUvarint(r)
+		if err != nil {
+			return state{}, err
+		}
+		recipe, err := binary.ReadUvarint(r)
+		if err != nil {
+			return state{}, err
+		}
+		bindings[i] = binding{element: int32(element), recipe: int32(recipe)}
+	}
+	var s state
+	for i := len(bindings) - 1; i >= 0; i-- {
+		bindings[i].parent = s.recipes
+		bindings[i].refs.Store(1)
+		s.recipes = &bindings[i]
+	}
+
+	pending, err := binary.ReadUvarint(r)
+	if err != nil {
+		return state{}, err
+	}
+	s.pending = make([]int32, pending)
+	for i := range s.pending {
+		id, err := binary.ReadUvarint(r)
+		if err != nil {
+			return state{}, err
+		}
+		s.pending[i] = int32(id)
+	}
+	return s, nil
+}
+
+// readChunk decodes every state of a spilled chunk, on error the states
+// read so far are returned
+func readChunk(path string) ([]state, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	states := make([]state, 0, chunkSize)
+	for {
+		if _, err := r.Peek(1); err == io.EOF {
+			break
+		}
+		s, err := decodeState(r)
+		if err != nil {
+			return states, fmt.Errorf("reading %s: %w", path, err)
+		}
+		states = append(states, s)
+	}
+	return states, nil
+}
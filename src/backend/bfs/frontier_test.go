@@ -0,0 +1,173 @@
+package bfs
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"recipe-finder/model"
+	"testing"
+)
+
+type bindingPair = [2]int32
+
+// chain builds a state with a binding per pair of element and recipe
+func chain(pending []int32, bindings ...bindingPair) state {
+	var s state
+	for _, b := range bindings {
+		s = s.with(b[0], b[1], nil)
+	}
+	s.pending = pending
+	return s
+}
+
+func bindings(s state) []bindingPair {
+	var pairs []bindingPair
+	for b := s.recipes; b != nil; b = b.parent {
+		pairs = append(pairs, bindingPair{b.element, b.recipe})
+	}
+	return pairs
+}
+
+func sameState(a, b state) bool {
+	pa, pb := bindings(a), bindings(b)
+	if len(pa) != len(pb) || len(a.pending) != len(b.pending) {
+		return false
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			return false
+		}
+	}
+	for i := range a.pending {
+		if a.pending[i] != b.pending[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEncodeState(t *testing.T) {
+	tests := []state{
+		{},
+		chain([]int32{3}),
+		chain(nil, bindingPair{7, 0}),
+		chain([]int32{1, 2, 300}, bindingPair{5, 1}, bindingPair{6, 0}, bindingPair{1 << 20, 129}),
+	}
+
+	for i, s := range tests {
+		encoded := encodeState(nil, s)
+		decoded, err := decodeState(bufio.NewReader(bytes.NewReader(encoded)))
+		if err != nil {
+			t.Errorf("decodeState(%d) failed: %v", i, err)
+			continue
+		}
+		if !sameState(s, decoded) {
+			t.Errorf("decodeState(%d) = %v %v, want %v %v", i, bindings(decoded), decoded.pending, bindings(s), s.pending)
+		}
+
+		if len(encoded) > 1 {
+			_, err := decodeState(bufio.NewReader(bytes.NewReader(encoded[:len(encoded)-1])))
+			if err == nil {
+				t.Errorf("decodeState(%d) accepted a truncated state", i)
+			}
+		}
+	}
+}
+
+func TestFrontierSpill(t *testing.T) {
+	tests := []struct {
+		name     string
+		spillDir string
+		states   int
+	}{
+		{"memory", "", 3*chunkSize + 17},
+		{"spill", t.TempDir(), 3*chunkSize + 17},
+		{"one chunk", t.TempDir(), chunkSize - 1},
+	}
+
+	for _, tt := range tests {
+		const maxMemory = 1 << 30
+		budget := model.NewBudget(model.Options{MaxMemory: maxMemory})
+		f := newFrontier(model.Options{SpillDir: tt.spillDir, SpillAfter: chunkSize}, budget)
+
+		// Semua state berbagi parent yang sudah dipop, seperti saat BFS mengexpand
+		f.push(chain(nil, bindingPair{1, 0}))
+		root, _ := f.pop()
+		var want []state
+		for i := 0; i < tt.states; i++ {
+			s := root.with(int32(i%50), int32(i), []int32{int32(i), 2})
+			want = append(want, s)
+			if !f.push(s) {
+				t.Fatalf("%s: push %d was pruned", tt.name, i)
+			}
+		}
+		release(budget, root.recipes)
+		if f.Len() != tt.states {
+			t.Errorf("%s: Len = %d, want %d", tt.name, f.Len(), tt.states)
+		}
+
+		spilled := 0
+		if tt.spillDir != "" {
+			files, _ := os.ReadDir(tt.spillDir)
+			spilled = len(files)
+		}
+		if tt.spillDir != "" && tt.states > 2*chunkSize && spilled == 0 {
+			t.Errorf("%s: nothing was spilled", tt.name)
+		}
+
+		for i, w := range want {
+			s, ok := f.pop()
+			if !ok {
+				t.Fatalf("%s: frontier empty after %d of %d states", tt.name, i, tt.states)
+			}
+			if !sameState(s, w) {
+				t.Fatalf("%s: pop %d = %v %v, want %v %v", tt.name, i, bindings(s), s.pending, bindings(w), w.pending)
+			}
+			release(budget, s.recipes)
+		}
+		if _, ok := f.pop(); ok {
+			t.Errorf("%s: frontier not empty after every state was popped", tt.name)
+		}
+		if f.lost != 0 {
+			t.Errorf("%s: lost %d states", tt.name, f.lost)
+		}
+		f.close()
+
+		// Semua memori sudah dikembalikan, jadi tepat seluruh budget bisa dipakai lagi
+		if budget.Reserve(maxMemory+1) || !budget.Reserve(maxMemory) {
+			t.Errorf("%s: memory budget not released after the frontier was drained", tt.name)
+		}
+		if files, _ := os.ReadDir(tt.spillDir); tt.spillDir != "" && len(files) != 0 {
+			t.Errorf("%s: %d spill files left behind", tt.name, len(files))
+		}
+	}
+}
+
+func TestFrontierBudget(t *testing.T) {
+	budget := model.NewBudget(model.Options{MaxMemory: 10 * (stateOverhead + bindingSize + 8)})
+	f := newFrontier(model.Options{}, budget)
+
+	pushed := 0
+	for i := 0; i < 20; i++ {
+		if f.push(state{}.with(int32(i), 0, make([]int32, 2))) {
+			pushed++
+		}
+	}
+	if pushed != 10 || budget.Pruned() != 10 || budget.Hit() != model.BudgetMemory {
+		t.Fatalf("pushed %d, pruned %d, hit %q; want 10, 10, %q", pushed, budget.Pruned(), budget.Hit(), model.BudgetMemory)
+	}
+
+	// Budget yang dilepas oleh pop bisa dipakai lagi oleh push berikutnya
+	for i := 0; i < 5; i++ {
+		s, _ := f.pop()
+		release(budget, s.recipes)
+	}
+	for i := 0; i < 5; i++ {
+		if !f.push(state{}.with(int32(i), 0, make([]int32, 2))) {
+			t.Fatalf("push %d after pop was pruned", i)
+		}
+	}
+	if f.push(state{}.with(0, 0, make([]int32, 2))) {
+		t.Errorf("push beyond the budget was not pruned")
+	}
+}
@@ -0,0 +1,396 @@
+package bidirectional
+
+import (
+	"context"
+	"log"
+	"recipe-finder/graph"
+	"recipe-finder/model"
+	"sort"
+	"strings"
+	"time"
+)
+
+// state is a partial recipe on the backward side, queue holds the
+// elements that still have to be expanded
+type state struct {
+	recipeMap map[string][]string
+	queue     []string
+}
+
+type searcher struct {
+	ctx   context.Context
+	g     *graph.RecipeGraph
+	limit int
+	opts  model.Options
+
+	// Elemen dengan tier <= meetTier diselesaikan oleh pencarian maju
+	meetTier int
+	solved   map[string]bool
+	subtrees map[string][]map[string][]string
+	// subtreeCap is the most subtrees kept per solved element, capped is
+	// set when an element had more
+	subtreeCap int
+	capped     bool
+
+	result []map[string][]string
+	// seen maps the fingerprint of every recipe found to the round it was
+	// last found in
+	seen    map[string]int
+	round   int
+	metrics model.Metrics
+}
+
+// SearchBidirectional searches forward from the base elements and backward
+// from the target element at the same time. The forward side solves every
+// element up to half of the target's tier, the backward side expands the
+// target until only solved elements are left, and both halves are joined
+// into complete recipe maps.
+func SearchBidirectional(ctx context.Context, g *graph.RecipeGraph, element string, maxRecipe int, opts model.Options) model.Result {
+	element = strings.TrimSpace(element)
+	log.Println("Starting bidirectional search for element:", element)
+	startTime := time.Now()
+
+	if !g.Has(element) {
+		duration := time.Since(startTime)
+		log.Println("Element not found in recipe graph")
+		log.Printf("Bidirectional search took %s", duration)
+		return model.Result{Duration: duration.Seconds()}
+	}
+
+	elementTier := g.Tier(element)
+	if opts.IsLeaf(g, element) || len(g.Recipes(element)) == 0 {
+		recipeMap := map[string][]string{element: {}}
+		if opts.OnRecipe != nil {
+			opts.OnRecipe(recipeMap)
+		}
+		duration := time.Since(startTime)
+		log.Printf("Bidirectional search took %s", duration)
+		return model.Result{
+			Recipes:     []map[string][]string{recipeMap},
+			Duration:    duration.Seconds(),
+			VisitedNode: 1,
+		}
+	}
+
+	s := &searcher{
+		ctx:        ctx,
+		g:          g,
+		limit:      maxRecipe,
+		opts:       opts,
+		meetTier:   elementTier / 2,
+		solved:     make(map[string]bool),
+		subtrees:   make(map[string][]map[string][]string),
+		subtreeCap: maxRecipe,
+		seen:       make(map[string]int),
+	}
+	s.forward()
+	s.backward(element)
+	for len(s.result) < maxRecipe && s.capped && ctx.Err() == nil {
+		// Subtree yang terpotong bisa bertabrakan saat digabung, ulangi dengan batas dua kali lipat
+		s.subtreeCap *= 2
+		s.subtrees = make(map[string][]map[string][]string)
+		s.capped = false
+		s.round++
+		s.backward(element)
+	}
+
+	truncated := ctx.Err() != nil && len(s.result) < maxRecipe
+	if truncated {
+		log.Printf("Bidirectional search stopped early: %v", ctx.Err())
+	}
+
+	duration := time.Since(startTime)
+	log.Printf("Bidirectional search took %s", duration)
+
+	stats := s.metrics.Stats()
+	return model.Result{
+		Recipes:     s.result,
+		Duration:    duration.Seconds(),
+		VisitedNode: stats.VisitedNodes(),
+		Truncated:   truncated,
+		Stats:       stats,
+	}
+}
+
+// forward marks every element up to meetTier that can be made from the
+// base and owned elements, walking the "used in" index outward from them
+func (s *searcher) forward() {
+	var queue []string
+	for _, base := range s.g.ElementsInTier(0) {
+		s.solved[base] = true
+		queue = append(queue, base)
+	}
+	for owned := range s.opts.Owned {
+		if s.g.Has(owned) && !s.solved[owned] {
+			s.solved[owned] = true
+			queue = append(queue, owned)
+		}
+	}
+
+	for len(queue) > 0 && s.ctx.Err() == nil {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, next := range s.g.UsedIn(current) {
+			tier := s.g.Tier(next)
+			if s.solved[next] || tier > s.meetTier {
+				continue
+			}
+			for _, recipe := range s.opts.Recipes(s.g, next) {
+				s.metrics.Consider()
+				if s.isSolvedIngredient(recipe[0], tier) && s.isSolvedIngredient(recipe[1], tier) {
+					s.solved[next] = true
+					queue = append(queue, next)
+					break
+				}
+			}
+		}
+	}
+}
+
+// backward expands the target breadth first, treating solved elements as leaves
+func (s *searcher) backward(element string) {
+	elementTier := s.g.Tier(element)
+	var queue []state
+
+	s.metrics.Expand()
+	for _, recipe := range s.opts.Recipes(s.g, element) {
+		s.metrics.Consider()
+		if !s.isUsableIngredient(recipe[0], elementTier) || !s.isUsableIngredient(recipe[1], elementTier) {
+			continue
+		}
+
+		next := state{recipeMap: map[string][]string{element: recipe}}
+		for i, ing := range recipe {
+			if i == 1 && recipe[0] == recipe[1] {
+				break
+			}
+			if !s.isLeaf(ing) {
+				next.queue = append(next.queue, ing)
+			}
+		}
+		queue = append(queue, next)
+		s.metrics.Generate(len(queue))
+		s.progress(model.Event{Type: model.EventEnqueue, Element: element, Recipe: recipe, Frontier: len(queue)})
+	}
+
+	for len(queue) > 0 && len(s.result) < s.limit && s.ctx.Err() == nil {
+		current := queue[0]
+		queue = queue[1:]
+
+		// Lewati elemen yang resepnya sudah ada di peta
+		for len(current.queue) > 0 {
+			if _, ok := current.recipeMap[current.queue[0]]; !ok {
+				break
+			}
+			current.queue = current.queue[1:]
+		}
+
+		if len(current.queue) == 0 {
+			s.join(current.recipeMap)
+			continue
+		}
+
+		elementToExpand := current.queue[0]
+		rest := current.queue[1:]
+		s.metrics.Expand()
+		s.progress(model.Event{Type: model.EventExpand, Element: elementToExpand, Frontier: len(queue)})
+		tier := s.g.Tier(elementToExpand)
+
+		for _, recipe := range s.opts.Recipes(s.g, elementToExpand) {
+			s.metrics.Consider()
+			if !s.isUsableIngredient(recipe[0], tier) || !s.isUsableIngredient(recipe[1], tier) {
+				continue
+			}
+
+			newRecipeMap := make(map[string][]string, len(current.recipeMap)+1)
+			for key, value := range current.recipeMap {
+				newRecipeMap[key] = value
+			}
+			newRecipeMap[elementToExpand] = recipe
+
+			newQueue := append([]string(nil), rest...)
+			for i, ing := range recipe {
+				if i == 1 && recipe[0] == recipe[1] {
+					break
+				}
+				if _, ok := newRecipeMap[ing]; ok || s.isLeaf(ing) || contains(newQueue, ing) {
+					continue
+				}
+				newQueue = append(newQueue, ing)
+			}
+
+			queue = append(queue, state{recipeMap: newRecipeMap, queue: newQueue})
+			s.metrics.Generate(len(queue))
+			s.progress(model.Event{Type: model.EventEnqueue, Element: elementToExpand, Recipe: recipe, Frontier: len(queue)})
+		}
+	}
+}
+
+func (s *searcher) progress(event model.Event) {
+	if s.opts.OnProgress != nil {
+		s.opts.OnProgress(event)
+	}
+}
+
+// join completes a backward recipe map with the forward subtrees of every
+// solved element it still depends on
+func (s *searcher) join(recipeMap map[string][]string) {
+	var meeting []string
+	for _, recipe := range recipeMap {
+		for _, ing := range recipe {
+			if !s.opts.IsLeaf(s.g, ing) && !contains(meeting, ing) {
+				if _, ok := recipeMap[ing]; !ok {
+					meeting = append(meeting, ing)
+				}
+			}
+		}
+	}
+	sort.Strings(meeting)
+
+	s.combine(recipeMap, meeting)
+}
+
+func (s *searcher) combine(merged map[string][]string, meeting []string) {
+	if len(s.result) >= s.limit || s.ctx.Err() != nil {
+		return
+	}
+
+	if len(meeting) == 0 {
+		fingerprint := model.Fingerprint(merged)
+		round, ok := s.seen[fingerprint]
+		s.seen[fingerprint] = s.round
+		if ok && round == s.round {
+			s.metrics.Duplicate()
+		} else if !ok {
+			s.result = append(s.result, merged)
+			if s.opts.OnRecipe != nil {
+				s.opts.OnRecipe(merged)
+			}
+		}
+		return
+	}
+
+	// Elemen bisa sudah masuk lewat subtree elemen temu sebelumnya
+	if _, ok := merged[meeting[0]]; ok {
+		s.combine(merged, meeting[1:])
+		return
+	}
+
+	for _, subtree := range s.subtreesOf(meeting[0]) {
+		if !consistent(merged, subtree) {
+			continue
+		}
+		next := make(map[string][]string, len(merged)+len(subtree))
+		for key, value := range merged {
+			next[key] = value
+		}
+		for key, value := range subtree {
+			next[key] = value
+		}
+		s.combine(next, meeting[1:])
+		if len(s.result) >= s.limit {
+			return
+		}
+	}
+}
+
+// subtreesOf lists up to subtreeCap distinct recipe maps for a solved
+// element, built bottom up from its ingredients' subtrees. Only subtrees
+// that can be written as one map count toward the cap.
+func (s *searcher) subtreesOf(element string) []map[string][]string {
+	if trees, ok := s.subtrees[element]; ok {
+		return trees
+	}
+
+	var trees []map[string][]string
+	seen := make(map[string]bool)
+	tier := s.g.Tier(element)
+
+	for _, recipe := range s.opts.Recipes(s.g, element) {
+		s.metrics.Consider()
+		if !s.isSolvedIngredient(recipe[0], tier) || !s.isSolvedIngredient(recipe[1], tier) {
+			continue
+		}
+
+		for _, left := range s.ingredientTrees(recipe[0]) {
+			for _, right := range s.ingredientTrees(recipe[1]) {
+				if !consistent(left, right) {
+					continue
+				}
+
+				tree := make(map[string][]string, len(left)+len(right)+1)
+				for key, value := range left {
+					tree[key] = value
+				}
+				for key, value := range right {
+					tree[key] = value
+				}
+				tree[element] = recipe
+
+				fingerprint := model.Fingerprint(tree)
+				if seen[fingerprint] {
+					continue
+				}
+				if len(trees) == s.subtreeCap {
+					s.capped = true
+					s.subtrees[element] = trees
+					return trees
+				}
+				seen[fingerprint] = true
+				trees = append(trees, tree)
+			}
+		}
+	}
+
+	s.subtrees[element] = trees
+	return trees
+}
+
+func (s *searcher) ingredientTrees(element string) []map[string][]string {
+	if s.opts.IsLeaf(s.g, element) {
+		return []map[string][]string{{}}
+	}
+	return s.subtreesOf(element)
+}
+
+// isLeaf reports whether the backward side can stop at this element
+func (s *searcher) isLeaf(element string) bool {
+	return s.opts.IsLeaf(s.g, element) || s.solved[element]
+}
+
+// isUsableIngredient checks an ingredient on the backward side, elements at
+// or below meetTier must have been reached by the forward side
+func (s *searcher) isUsableIngredient(ingredient string, parentTier int) bool {
+	tier := s.g.Tier(ingredient)
+	if tier >= parentTier {
+		return false
+	}
+	return tier > s.meetTier || s.solved[ingredient] || s.opts.Owned[ingredient]
+}
+
+func (s *searcher) isSolvedIngredient(ingredient string, parentTier int) bool {
+	return s.g.Tier(ingredient) < parentTier && s.solved[ingredient]
+}
+
+func consistent(a, b map[string][]string) bool {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	for key, value := range a {
+		other, ok := b[key]
+		if ok && !model.SameRecipe(value, other) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, target string) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package bidirectional
+
+import (
+	"context"
+	"recipe-finder/graph"
+	"recipe-finder/graph/graphtest"
+	"recipe-finder/model"
+	"testing"
+)
+
+// sharedGraph makes Tower from Arch, which is solved by the forward
+// search. Both ingredients of Arch use Post, so only two of the four
+// pairs of their subtrees agree on the recipe of Post.
+func sharedGraph() *graph.RecipeGraph {
+	return graph.New(map[string]graph.Recipe{
+		"Air":   {Tier: 0},
+		"Earth": {Tier: 0},
+		"Fire":  {Tier: 0},
+		"Water": {Tier: 0},
+		"Post":  {Tier: 1, Recipes: [][]string{{"Earth", "Fire"}, {"Earth", "Water"}}},
+		"Beam":  {Tier: 2, Recipes: [][]string{{"Air", "Post"}}},
+		"Pier":  {Tier: 2, Recipes: [][]string{{"Post", "Water"}}},
+		"Arch":  {Tier: 3, Recipes: [][]string{{"Beam", "Pier"}}},
+		"Wall":  {Tier: 4, Recipes: [][]string{{"Arch", "Fire"}, {"Arch", "Post"}}},
+		"Gate":  {Tier: 5, Recipes: [][]string{{"Air", "Wall"}}},
+		"Tower": {Tier: 6, Recipes: [][]string{{"Gate", "Water"}}},
+	})
+}
+
+func TestSearchBidirectional(t *testing.T) {
+	tests := []struct {
+		name      string
+		g         *graph.RecipeGraph
+		element   string
+		maxRecipe int
+		owned     []string
+		want      int
+	}{
+		{"base", graphtest.Small(), "Water", 3, nil, 1},
+		{"small", graphtest.Small(), "Rain", 10, nil, 6},
+		{"small limit", graphtest.Small(), "Rain", 4, nil, 4},
+		// Hanya subtree Arch yang konsisten yang digabung
+		{"shared", sharedGraph(), "Tower", 10, nil, 4},
+		{"shared limit", sharedGraph(), "Tower", 1, nil, 1},
+		{"shared meeting", sharedGraph(), "Wall", 10, nil, 4},
+		// Elemen yang dimiliki tidak perlu dibuat lagi
+		{"owned", sharedGraph(), "Tower", 10, []string{"Arch"}, 3},
+		{"owned post", sharedGraph(), "Tower", 10, []string{"Post"}, 2},
+		{"missing", sharedGraph(), "Bridge", 10, nil, 0},
+	}
+
+	for _, tt := range tests {
+		opts := model.Options{Deterministic: true}
+		if tt.owned != nil {
+			opts.Owned = make(map[string]bool)
+			for _, element := range tt.owned {
+				opts.Owned[element] = true
+			}
+		}
+		result := SearchBidirectional(context.Background(), tt.g, tt.element, tt.maxRecipe, opts)
+		if len(result.Recipes) != tt.want {
+			t.Errorf("%s: found %d recipes, want %d", tt.name, len(result.Recipes), tt.want)
+		}
+		if result.Truncated {
+			t.Errorf("%s: result is truncated", tt.name)
+		}
+		if result.Stats.Duplicates != 0 {
+			t.Errorf("%s: %d duplicates", tt.name, result.Stats.Duplicates)
+		}
+		for i, recipe := range result.Recipes {
+			checkRecipe(t, tt.g, tt.name, i, tt.element, recipe, opts)
+		}
+	}
+}
+
+// checkRecipe verifies that recipe makes element with exactly one recipe
+// per non-leaf element and no unused entries
+func checkRecipe(t *testing.T, g *graph.RecipeGraph, name string, i int, element string, recipe map[string][]string, opts model.Options) {
+	t.Helper()
+	used := map[string]bool{}
+	stack := []string{element}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if used[current] || opts.IsLeaf(g, current) {
+			continue
+		}
+		used[current] = true
+		ings, ok := recipe[current]
+		if !ok {
+			t.Errorf("%s: recipe %d has no entry for %s", name, i, current)
+			continue
+		}
+		stack = append(stack, ings...)
+	}
+	if len(used) != len(recipe) && !(len(recipe) == 1 && len(used) == 0) {
+		t.Errorf("%s: recipe %d has %d entries but uses %d", name, i, len(recipe), len(used))
+	}
+}
+
+func TestSubtreesOf(t *testing.T) {
+	tests := []struct {
+		cap    int
+		want   int
+		capped bool
+	}{
+		{1, 1, true},
+		// Pasangan yang tidak konsisten tidak dihitung, jadi dua subtree sudah semuanya
+		{2, 2, false},
+		{4, 2, false},
+	}
+
+	for _, tt := range tests {
+		s := &searcher{
+			ctx:        context.Background(),
+			g:          sharedGraph(),
+			limit:      10,
+			meetTier:   3,
+			solved:     make(map[string]bool),
+			subtrees:   make(map[string][]map[string][]string),
+			subtreeCap: tt.cap,
+			seen:       make(map[string]int),
+		}
+		s.forward()
+		trees := s.subtreesOf("Arch")
+		if len(trees) != tt.want || s.capped != tt.capped {
+			t.Errorf("cap %d: %d subtrees, capped %v; want %d, %v", tt.cap, len(trees), s.capped, tt.want, tt.capped)
+		}
+		for _, tree := range trees {
+			if len(tree) != 4 {
+				t.Errorf("cap %d: subtree %v does not share Post", tt.cap, tree)
+			}
+		}
+	}
+}
+
+func TestSearchBidirectionalCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := SearchBidirectional(ctx, sharedGraph(), "Tower", 5, model.Options{})
+	if !result.Truncated || len(result.Recipes) != 0 {
+		t.Errorf("cancelled search found %d recipes, truncated %v", len(result.Recipes), result.Truncated)
+	}
+}
@@ -0,0 +1,103 @@
+// Package cache keeps recent search results in memory so popular targets
+// are not searched again on every request.
+package cache
+
+import (
+	"container/list"
+	"recipe-finder/model"
+	"sync"
+	"time"
+)
+
+// Cache is a least-recently-used cache of search results with an optional
+// time to live. It is safe for concurrent use. A nil *Cache is valid and
+// never stores anything, which is how caching is disabled.
+type Cache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	items map[string]*list.Element
+	order *list.List // front is the most recently used
+}
+
+type entry struct {
+	key     string
+	result  model.Result
+	expires time.Time
+}
+
+// New creates a cache holding at most size results, each for at most ttl.
+// A ttl of 0 keeps results until they are evicted, a size below 1 returns
+// nil.
+func New(size int, ttl time.Duration) *Cache {
+	if size < 1 {
+		return nil
+	}
+	return &Cache{
+		size:  size,
+		ttl:   ttl,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+// Get returns the result stored under key if it has not expired yet
+func (c *Cache) Get(key string) (model.Result, bool) {
+	if c == nil {
+		return model.Result{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[key]
+	if !ok {
+		return model.Result{}, false
+	}
+	e := item.Value.(*entry)
+	if c.ttl > 0 && time.Now().After(e.expires) {
+		c.remove(item)
+		return model.Result{}, false
+	}
+	c.order.MoveToFront(item)
+	return e.result, true
+}
+
+// Add stores result under key, evicting the least recently used result
+// when the cache is full
+func (c *Cache) Add(key string, result model.Result) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(c.ttl)
+	if item, ok := c.items[key]; ok {
+		e := item.Value.(*entry)
+		e.result, e.expires = result, expires
+		c.order.MoveToFront(item)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry{key: key, result: result, expires: expires})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// Purge drops every result, used when the recipe data changes
+func (c *Cache) Purge() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+func (c *Cache) remove(item *list.Element) {
+	c.order.Remove(item)
+	delete(c.items, item.Value.(*entry).key)
+}
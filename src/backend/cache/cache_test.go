@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"recipe-finder/model"
+	"testing"
+	"time"
+)
+
+func result(visited int) model.Result {
+	return model.Result{VisitedNode: visited}
+}
+
+func TestEviction(t *testing.T) {
+	type op struct {
+		add  string
+		get  string
+		want bool
+	}
+	tests := []struct {
+		name string
+		size int
+		ops  []op
+	}{
+		{"oldest evicted", 2, []op{
+			{add: "a"}, {add: "b"}, {add: "c"},
+			{get: "a", want: false}, {get: "b", want: true}, {get: "c", want: true},
+		}},
+		{"get refreshes", 2, []op{
+			{add: "a"}, {add: "b"}, {get: "a", want: true}, {add: "c"},
+			{get: "a", want: true}, {get: "b", want: false}, {get: "c", want: true},
+		}},
+		{"add refreshes", 2, []op{
+			{add: "a"}, {add: "b"}, {add: "a"}, {add: "c"},
+			{get: "a", want: true}, {get: "b", want: false},
+		}},
+		{"size one", 1, []op{
+			{add: "a"}, {add: "b"}, {get: "a", want: false}, {get: "b", want: true},
+		}},
+	}
+
+	for _, tt := range tests {
+		c := New(tt.size, 0)
+		for i, o := range tt.ops {
+			if o.add != "" {
+				c.Add(o.add, result(i))
+				continue
+			}
+			if _, ok := c.Get(o.get); ok != o.want {
+				t.Errorf("%s: Get(%q) at step %d = %v, want %v", tt.name, o.get, i, ok, o.want)
+			}
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	c := New(2, 0)
+	c.Add("a", result(1))
+	c.Add("a", result(2))
+	if got, ok := c.Get("a"); !ok || got.VisitedNode != 2 {
+		t.Errorf("Get(a) = %v, %v, want the latest result", got.VisitedNode, ok)
+	}
+
+	c.Purge()
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Get(a) after Purge found a result")
+	}
+}
+
+func TestTTL(t *testing.T) {
+	const ttl = 100 * time.Millisecond
+	c := New(4, ttl)
+	c.Add("old", result(1))
+	time.Sleep(60 * time.Millisecond)
+	c.Add("new", result(2))
+	time.Sleep(50 * time.Millisecond)
+
+	if _, ok := c.Get("old"); ok {
+		t.Errorf("Get(old) found an expired result")
+	}
+	if _, ok := c.Get("new"); !ok {
+		t.Errorf("Get(new) expired too early")
+	}
+
+	// Add lagi memperpanjang waktu berlakunya
+	c.Add("new", result(3))
+	time.Sleep(60 * time.Millisecond)
+	if got, ok := c.Get("new"); !ok || got.VisitedNode != 3 {
+		t.Errorf("Get(new) after Add = %v, %v, want 3, true", got.VisitedNode, ok)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		c := New(size, time.Minute)
+		if c != nil {
+			t.Fatalf("New(%d) = %v, want nil", size, c)
+		}
+		c.Add("a", result(1))
+		c.Purge()
+		if _, ok := c.Get("a"); ok {
+			t.Errorf("nil cache returned a result")
+		}
+	}
+}
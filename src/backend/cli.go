@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"recipe-finder/bfs"
+	"recipe-finder/export"
+	"recipe-finder/graph"
+	"recipe-finder/model"
+	"recipe-finder/scrape"
+	"strings"
+	"time"
+)
+
+// commands are the subcommands of the binary, serve runs when none is given
+var commands = map[string]func(args []string) error{
+	"serve":    runServe,
+	"search":   runSearch,
+	"scrape":   runScrape,
+	"diff":     runDiff,
+	"elements": runElements,
+}
+
+const usage = `Usage: recipe-finder [command] [flags]
+
+Commands:
+  serve      run the HTTP server (default)
+  search     find recipes, e.g. recipe-finder search --algo dfs --max 5 Brick
+  scrape     scrape the wiki into the recipe JSON
+  diff       compare a new scrape with the current recipe JSON
+  elements   list elements, e.g. recipe-finder elements --tier 3
+
+Run "recipe-finder <command> -h" for the flags of a command.
+`
+
+// loadGraph loads the recipe JSON at path, or the data of the built-in
+// source when path is empty. Like a server reload it rejects data the
+// searchers cannot handle with a *graph.ValidationError.
+func loadGraph(source, path string) (*graph.RecipeGraph, error) {
+	if path == "" {
+		src, err := scrape.LookupSource(source)
+		if err != nil {
+			return nil, err
+		}
+		path = src.DataPath
+	}
+	g, err := graph.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// datasetName names the data read by loadGraph in responses, a custom
+// file is named after its file name
+func datasetName(source, path string) string {
+	if path != "" {
+		return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return source
+}
+
+// cliError turns a validation error into a message for the terminal
+func cliError(err *apiError) error {
+	if len(err.Suggestions) > 0 {
+		return fmt.Errorf("%s, did you mean: %s", err.Message, strings.Join(err.Suggestions, ", "))
+	}
+	return fmt.Errorf("%s", err.Message)
+}
+
+func runSearch(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	algo := fs.String("algo", "bfs", "search algorithm: bfs, dfs or bidirectional")
+	maxRecipe := fs.Int("max", 1, "maximum number of recipes")
+	mode := fs.String("mode", "", `"shortest" ranks recipes by their number of combinations`)
+	owned := fs.String("owned", "", "comma separated elements that are already owned")
+	asJSON := fs.Bool("json", false, "print the API response as JSON instead of ASCII trees")
+	deterministic := fs.Bool("deterministic", false, "use a single worker so the output is identical every run")
+	special := fs.Bool("special", false, "allow recipes that need a special element such as Time")
+	spillDir := fs.String("spill-dir", "", "let BFS spill its frontier to temporary files in this directory instead of keeping it in memory")
+	spillAfter := fs.Int("spill-after", bfs.DefaultSpillAfter, "number of BFS frontier states kept in memory before spilling")
+	maxNodes := fs.Int("max-nodes", 0, "maximum number of nodes BFS or DFS may visit, 0 disables the limit")
+	maxMemory := fs.Int("max-memory-mb", 0, "maximum estimated megabytes of states BFS or DFS may hold, 0 disables the limit")
+	format := fs.String("format", formatFlat, "shape of the JSON results: flat or tree")
+	timeout := fs.Duration("timeout", 30*time.Second, "maximum duration of the search, 0 disables the limit")
+	source := fs.String("dataset", scrape.LittleAlchemy2.Name, "built-in dataset to search in: "+strings.Join(scrape.SourceNames(), ", "))
+	dataPath := fs.String("data", "", "recipe JSON to search in, overrides -dataset")
+	verbose := fs.Bool("v", false, "show the search logs")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: recipe-finder search [flags] <element>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("missing element")
+	}
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	g, err := loadGraph(*source, *dataPath)
+	if err != nil {
+		return err
+	}
+
+	req := RecipeRequest{
+		Element:        strings.Join(fs.Args(), " "),
+		Algorithm:      *algo,
+		MaxRecipe:      *maxRecipe,
+		Mode:           *mode,
+		Format:         *format,
+		Deterministic:  *deterministic,
+		IncludeSpecial: *special,
+		MaxNodes:       *maxNodes,
+		MaxMemoryMB:    *maxMemory,
+		Dataset:        datasetName(*source, *dataPath),
+	}
+	for _, name := range strings.Split(*owned, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			req.Owned = append(req.Owned, name)
+		}
+	}
+	if err := validateRequest(g, &req); err != nil {
+		return cliError(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if *timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), *timeout)
+	}
+	defer cancel()
+
+	result := exploreRecipes(ctx, g, req, model.Options{
+		SpillDir:   *spillDir,
+		SpillAfter: *spillAfter,
+		MaxNodes:   req.MaxNodes,
+		MaxMemory:  int64(req.MaxMemoryMB) << 20,
+	})
+	if err := timeoutError(ctx, req, result); err != nil {
+		return cliError(err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(recipeResponse(g, req, result))
+	}
+
+	trees := make([]*model.TreeNode, len(result.Recipes))
+	for i, recipe := range result.Recipes {
+		trees[i] = model.BuildTree(g, req.Element, recipe)
+	}
+	fmt.Print(export.Text(trees))
+	// Statistik ke stderr supaya output tree bisa di-diff antar run
+	fmt.Fprintf(os.Stderr, "%d recipe(s) in %.3fs, %d nodes visited, %d states expanded, %d generated, peak frontier %d",
+		len(result.Recipes), result.Duration, result.VisitedNode, result.Stats.Expanded, result.Stats.Generated, result.Stats.PeakFrontier)
+	if result.Truncated {
+		fmt.Fprint(os.Stderr, ", truncated")
+	}
+	if result.Budget != "" {
+		fmt.Fprintf(os.Stderr, ", %s budget hit, %d states pruned", result.Budget, result.Pruned)
+	}
+	fmt.Fprintln(os.Stderr)
+	return nil
+}
+
+// scrapeElements scrapes either a saved snapshot or the live wiki page of
+// the named source
+func scrapeElements(source, htmlPath string) (map[string]scrape.ElementData, error) {
+	src, err := scrape.LookupSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var elements map[string]scrape.ElementData
+	if htmlPath != "" {
+		elements, err = src.ScrapeFile(htmlPath)
+	} else {
+		elements, err = src.Scrape()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scraping recipes: %w", err)
+	}
+	return elements, nil
+}
+
+// writeJSONFile writes v as indented JSON, "-" writes to stdout
+func writeJSONFile(path string, v any) error {
+	out := os.Stdout
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func runScrape(args []string) error {
+	fs := flag.NewFlagSet("scrape", flag.ExitOnError)
+	source := fs.String("source", scrape.LittleAlchemy2.Name, "dataset to scrape: "+strings.Join(scrape.SourceNames(), ", "))
+	htmlPath := fs.String("html", "", "parse a saved HTML snapshot of the wiki instead of fetching it")
+	out := fs.String("out", "", "where to write the recipe JSON, defaults to the data path of the source")
+	reportPath := fs.String("report", "", "write every dropped recipe and element as JSON to this file, - for stdout")
+	verbose := fs.Bool("v", false, "print every dropped recipe and element")
+	dryRun := fs.Bool("dry-run", false, "only clean and report, do not write the recipe JSON")
+	fs.Parse(args)
+
+	elements, err := scrapeElements(*source, *htmlPath)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		*out = scrape.Sources[*source].DataPath
+	}
+	elements, report := scrape.CleanRecipesWithReport(elements)
+
+	if *verbose {
+		report.WriteText(os.Stderr)
+	} else {
+		fmt.Fprintf(os.Stderr, "%d recipe(s) dropped, %d element(s) left without a recipe\n", len(report.Recipes), len(report.Elements))
+	}
+	if *reportPath != "" {
+		if err := writeJSONFile(*reportPath, report); err != nil {
+			return fmt.Errorf("writing report: %w", err)
+		}
+	}
+	if *dryRun {
+		return nil
+	}
+
+	if err := scrape.WriteJson(elements, *out); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d elements to %s\n", len(elements), *out)
+	return nil
+}
+
+// runDiff compares a fresh scrape, or another JSON file, with the current
+// recipe data before it is replaced
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	source := fs.String("source", scrape.LittleAlchemy2.Name, "dataset to scrape: "+strings.Join(scrape.SourceNames(), ", "))
+	htmlPath := fs.String("html", "", "parse a saved HTML snapshot of the wiki instead of fetching it")
+	newPath := fs.String("new", "", "compare this recipe JSON instead of scraping")
+	oldPath := fs.String("old", "", "the current recipe JSON, defaults to the data path of the source")
+	asJSON := fs.Bool("json", false, "print the diff as JSON")
+	fs.Parse(args)
+
+	if *oldPath == "" {
+		src, err := scrape.LookupSource(*source)
+		if err != nil {
+			return err
+		}
+		*oldPath = src.DataPath
+	}
+	current, err := scrape.ReadJson(*oldPath)
+	if err != nil {
+		return err
+	}
+
+	var next map[string]scrape.ElementData
+	if *newPath != "" {
+		next, err = scrape.ReadJson(*newPath)
+	} else if next, err = scrapeElements(*source, *htmlPath); err == nil {
+		next = scrape.CleanRecipes(next)
+	}
+	if err != nil {
+		return err
+	}
+
+	diff := scrape.Diff(current, next)
+	if *asJSON {
+		return writeJSONFile("-", diff)
+	}
+	diff.WriteText(os.Stdout)
+	return nil
+}
+
+func runElements(args []string) error {
+	fs := flag.NewFlagSet("elements", flag.ExitOnError)
+	tier := fs.Int("tier", -1, "only list elements of this tier, -1 lists every tier")
+	prefix := fs.String("prefix", "", "only list elements whose name starts with this (case-insensitive)")
+	asJSON := fs.Bool("json", false, "print the elements as JSON")
+	source := fs.String("dataset", scrape.LittleAlchemy2.Name, "built-in dataset to list: "+strings.Join(scrape.SourceNames(), ", "))
+	dataPath := fs.String("data", "", "recipe JSON to read, overrides -dataset")
+	fs.Parse(args)
+
+	g, err := loadGraph(*source, *dataPath)
+	if err != nil {
+		return err
+	}
+
+	matches := filterElements(g, *tier, *prefix)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(matches)
+	}
+	for _, element := range matches {
+		fmt.Printf("%d\t%s\n", element.Tier, element.Name)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"recipe-finder/graph"
+	"recipe-finder/scrape"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// dataset is one named set of recipes served by the server
+type dataset struct {
+	name string
+	path string
+	// graph is swapped as a whole on reload, every request loads it once
+	// and keeps using that snapshot until it is done
+	graph atomic.Pointer[graph.RecipeGraph]
+}
+
+type datasetSummary struct {
+	Name     string `json:"name"`
+	Elements int    `json:"elements"`
+	MaxTier  int    `json:"maxTier"`
+	Default  bool   `json:"default"`
+}
+
+// datasetFlags collects repeated -dataset name=path flags in order
+type datasetFlags []struct{ name, path string }
+
+func (f *datasetFlags) String() string {
+	var specs []string
+	for _, spec := range *f {
+		specs = append(specs, spec.name+"="+spec.path)
+	}
+	return strings.Join(specs, ",")
+}
+
+func (f *datasetFlags) Set(value string) error {
+	name, path, ok := strings.Cut(value, "=")
+	if !ok || name == "" || path == "" {
+		return fmt.Errorf("expected name=path, got %q", value)
+	}
+	*f = append(*f, struct{ name, path string }{name, path})
+	return nil
+}
+
+// prepareData makes sure the recipe JSON of a built-in source exists
+// before the server starts. An existing file is reused as is unless
+// forceScrape is set.
+func prepareData(src scrape.Source, path, htmlPath string, forceScrape bool) error {
+	_, statErr := os.Stat(path)
+	dataExists := statErr == nil
+	if dataExists && !forceScrape {
+		log.Printf("Using existing %s, skipping scrape", path)
+		return nil
+	}
+
+	if err := src.ScrapeToJson(htmlPath, path); err != nil {
+		if dataExists {
+			log.Printf("Scrape failed, falling back to existing data: %v", err)
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// graphFor returns the current graph of the named dataset. An empty name
+// is replaced by the default dataset so the caller can echo it back.
+func (s *server) graphFor(name *string) (*graph.RecipeGraph, *apiError) {
+	if *name == "" {
+		*name = s.defaultDataset
+	}
+	ds, ok := s.datasets[*name]
+	if !ok {
+		return nil, newAPIError(http.StatusNotFound, codeUnknownDataset, "unknown dataset %q, expected one of %s", *name, strings.Join(s.datasetNames(), ", "))
+	}
+	return ds.graph.Load(), nil
+}
+
+func (s *server) datasetNames() []string {
+	names := make([]string, 0, len(s.datasets))
+	for name := range s.datasets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// handleDatasets lists the datasets a request can pick from
+func (s *server) handleDatasets(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	summaries := []datasetSummary{}
+	for _, name := range s.datasetNames() {
+		g := s.datasets[name].graph.Load()
+		summaries = append(summaries, datasetSummary{
+			Name:     name,
+			Elements: g.Len(),
+			MaxTier:  g.MaxTier(),
+			Default:  name == s.defaultDataset,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summaries)
+}
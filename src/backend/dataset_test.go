@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"recipe-finder/cache"
+	"recipe-finder/graph/graphtest"
+	"testing"
+	"time"
+)
+
+// newDatasetServer serves testdata/la2.json as the default dataset and
+// graphtest.Small as small
+func newDatasetServer(t *testing.T) *server {
+	t.Helper()
+	s := newTestServer(t, "la2=testdata/la2.json")
+	small := &dataset{name: "small"}
+	small.graph.Store(graphtest.Small())
+	s.datasets["small"] = small
+	return s
+}
+
+func TestDatasetFlags(t *testing.T) {
+	var f datasetFlags
+	for _, value := range []string{"la2=./data/la2.json", "mod=mods/a=b.json"} {
+		if err := f.Set(value); err != nil {
+			t.Errorf("Set(%q) failed: %v", value, err)
+		}
+	}
+	for _, value := range []string{"la2", "=path.json", "la2=", ""} {
+		if err := f.Set(value); err == nil {
+			t.Errorf("Set(%q) accepted a spec without name and path", value)
+		}
+	}
+	if got := f.String(); got != "la2=./data/la2.json,mod=mods/a=b.json" {
+		t.Errorf("String = %q", got)
+	}
+}
+
+func TestHandleDatasets(t *testing.T) {
+	s := newDatasetServer(t)
+	rec := httptest.NewRecorder()
+	s.handleDatasets(rec, httptest.NewRequest(http.MethodGet, "/api/datasets", nil))
+
+	var got []datasetSummary
+	json.NewDecoder(rec.Body).Decode(&got)
+	want := []datasetSummary{
+		{Name: "la2", Elements: 6, MaxTier: 2, Default: true},
+		{Name: "small", Elements: 10, MaxTier: 3},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("datasets = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("dataset %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRecipeDataset(t *testing.T) {
+	s := newDatasetServer(t)
+	s.results = cache.New(8, time.Minute)
+
+	tests := []struct {
+		dataset string
+		element string
+		status  int
+		want    string
+		recipes int
+	}{
+		// Tanpa dataset dipakai dataset default dan namanya dikembalikan
+		{"", "Cloud", http.StatusOK, "la2", 2},
+		{"la2", "Steam", http.StatusOK, "la2", 2},
+		{"small", "Cloud", http.StatusOK, "small", 2},
+		{"small", "Rain", http.StatusOK, "small", 6},
+		// Rain hanya ada di dataset small
+		{"la2", "Rain", http.StatusNotFound, codeUnknownElement, 0},
+		{"la1", "Cloud", http.StatusNotFound, codeUnknownDataset, 0},
+	}
+
+	for _, tt := range tests {
+		req := RecipeRequest{Element: tt.element, Algorithm: "bfs", MaxRecipe: 10, Dataset: tt.dataset}
+		var resp struct {
+			RecipeResponse
+			Error apiError `json:"error"`
+		}
+		if code := postRecipe(t, s, req, &resp); code != tt.status {
+			t.Errorf("%s/%s: status %d, want %d", tt.dataset, tt.element, code, tt.status)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			if resp.Error.Code != tt.want {
+				t.Errorf("%s/%s: error %q, want %q", tt.dataset, tt.element, resp.Error.Code, tt.want)
+			}
+			continue
+		}
+		// Elemen yang sama di dataset lain tidak boleh diambil dari cache
+		if resp.Dataset != tt.want || resp.Cache != cacheMiss {
+			t.Errorf("%s/%s: dataset %q, cache %q; want %q, miss", tt.dataset, tt.element, resp.Dataset, resp.Cache, tt.want)
+		}
+		if n := len(decodeRecipes(resp.Results)); n != tt.recipes {
+			t.Errorf("%s/%s: %d recipes, want %d", tt.dataset, tt.element, n, tt.recipes)
+		}
+	}
+}
+
+func TestElementsDataset(t *testing.T) {
+	s := newDatasetServer(t)
+	tests := []struct {
+		query  string
+		status int
+		total  int
+	}{
+		{"", http.StatusOK, 6},
+		{"?dataset=small", http.StatusOK, 10},
+		{"?dataset=small&tier=1", http.StatusOK, 3},
+		{"?dataset=la1", http.StatusNotFound, 0},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		s.handleElements(rec, httptest.NewRequest(http.MethodGet, "/api/elements"+tt.query, nil))
+		var resp elementListResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != tt.status || resp.Total != tt.total {
+			t.Errorf("%q: status %d with %d elements, want %d with %d", tt.query, rec.Code, resp.Total, tt.status, tt.total)
+		}
+	}
+}
@@ -2,66 +2,23 @@ package dfs
 
 import (
 	"container/list"
-	"encoding/json"
+	"context"
 	"log"
-	"os"
+	"recipe-finder/graph"
+	"recipe-finder/model"
 	"runtime"
-	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// Global mutex for RecipeData
-var recipeDataMutex sync.RWMutex
-var RecipeData map[string]Recipe
-
-type Recipe struct {
-	Tier    int        `json:"tier"`
-	Recipes [][]string `json:"recipes"`
-}
-
-func readRecipeJson() bool {
-	// Use mutex to protect RecipeData during initialization
-	recipeDataMutex.Lock()
-	defer recipeDataMutex.Unlock()
-
-	// Only read the file if RecipeData is nil
-	if RecipeData != nil {
-		return true
-	}
-
-	// Baca JSON
-	file, err := os.Open("./data/recipes_complete.json")
-	if err != nil {
-		log.Fatal(err)
-		return false
-	}
-	defer file.Close()
-
-	// Create a new map for RecipeData
-	tempRecipeData := make(map[string]Recipe)
-
-	// Convert
-	if err := json.NewDecoder(file).Decode(&tempRecipeData); err != nil {
-		log.Fatal(err)
-		return false
-	}
-
-	// Assign the loaded data to RecipeData
-	RecipeData = tempRecipeData
-	return true
-}
-
 // isRecipeComplete checks if a recipe map contains all necessary components
-func isRecipeComplete(recipeMap map[string][]string) bool {
-	recipeDataMutex.RLock()
-	defer recipeDataMutex.RUnlock()
-
+func isRecipeComplete(g *graph.RecipeGraph, opts model.Options, recipeMap map[string][]string) bool {
 	// Check each element in the recipe map
 	for element, components := range recipeMap {
-		// Skip checking base elements
-		if elementData, exists := RecipeData[element]; exists && elementData.Tier == 0 {
+		// Skip checking base and owned elements
+		if g.Has(element) && opts.IsLeaf(g, element) {
 			continue
 		}
 
@@ -72,14 +29,13 @@ func isRecipeComplete(recipeMap map[string][]string) bool {
 
 		// Check if all components exist and are properly expanded
 		for _, component := range components {
-			// Check if component exists in RecipeData
-			componentData, exists := RecipeData[component]
-			if !exists {
+			// Check if component exists in the graph
+			if !g.Has(component) {
 				return false
 			}
 
-			// If component is not a base element (tier > 0), it must be in the recipe map
-			if componentData.Tier > 0 {
+			// If component is not a base or owned element, it must be in the recipe map
+			if !opts.IsLeaf(g, component) {
 				if _, hasRecipe := recipeMap[component]; !hasRecipe {
 					return false
 				}
@@ -90,69 +46,79 @@ func isRecipeComplete(recipeMap map[string][]string) bool {
 	return true
 }
 
-// SearchDFS performs a depth-first search to find recipes for the given element
-func SearchDFS(element string, maxRecipe int) ([]map[string][]string, float64, int) {
-	progressLogInterval := 500
-	lastLogTime := time.Now()
-
-	recipeDataMutex.Lock()
-	if RecipeData == nil {
-		recipeDataMutex.Unlock()
-		if !readRecipeJson() {
-			log.Println("Error reading JSON file")
-			return nil, 0, 0
+// inStack reports whether element is already waiting on a state's stack
+func inStack(stack *list.List, element string) bool {
+	for el := stack.Front(); el != nil; el = el.Next() {
+		if el.Value.(string) == element {
+			return true
 		}
-	} else {
-		recipeDataMutex.Unlock()
 	}
+	return false
+}
+
+// stateSize estimates the memory held by one state on the stack: the state
+// map itself plus one entry per recipe and per element left to expand
+func stateSize(recipeMap map[string][]string, stack *list.List) int64 {
+	return 256 + 64*int64(len(recipeMap)) + 64*int64(stack.Len())
+}
+
+// SearchDFS performs a depth-first search to find recipes for the given element
+// The search stops early when ctx is cancelled, in which case the recipes
+// found so far are returned with Truncated set.
+func SearchDFS(ctx context.Context, g *graph.RecipeGraph, element string, maxRecipe int, opts model.Options) model.Result {
+	progressLogInterval := 500
+	var lastLogTime atomic.Int64
+	lastLogTime.Store(time.Now().UnixNano())
 
 	element = strings.TrimSpace(element)
-	nodeCount := 0
+	metrics := &model.Metrics{}
 	log.Println("Starting DFS for element:", element)
 	startTime := time.Now()
 
-	recipeDataMutex.RLock()
-	_, ok := RecipeData[element]
-	recipeDataMutex.RUnlock()
-
-	if !ok {
+	if !g.Has(element) {
 		duration := time.Since(startTime)
-		log.Println("Element not found in RecipeData")
+		log.Println("Element not found in recipe graph")
 		log.Printf("DFS took %s", duration)
-		return nil, float64(duration.Seconds()), 0
+		return model.Result{Duration: duration.Seconds()}
 	}
 
-	recipeDataMutex.RLock()
-	elementTier := RecipeData[element].Tier
-	recipeDataMutex.RUnlock()
+	elementTier := g.Tier(element)
 
 	var result []map[string][]string
 	var resultMutex sync.Mutex
 
-	if elementTier == 0 || len(RecipeData[element].Recipes) == 0 {
+	if opts.IsLeaf(g, element) || len(g.Recipes(element)) == 0 {
 		duration := time.Since(startTime)
 		result = append(result, map[string][]string{element: {}})
+		if opts.OnRecipe != nil {
+			opts.OnRecipe(result[0])
+		}
 		log.Printf("DFS took %s", duration)
-		return result, float64(duration.Seconds()), 1
+		return model.Result{Recipes: result, Duration: duration.Seconds(), VisitedNode: 1}
 	}
 
 	// Using a stack instead of a queue for DFS
 	recipeStack := list.New()
 	var stackMutex sync.Mutex
 
-	recipeDataMutex.RLock()
-	recipes := RecipeData[element].Recipes
-	recipeDataMutex.RUnlock()
+	progress := func(event model.Event) {
+		if opts.OnProgress != nil {
+			opts.OnProgress(event)
+		}
+	}
 
-	nodeCount++
+	budget := model.NewBudget(opts)
+	defer budget.Close()
+	recipes := opts.Recipes(g, element)
+
+	metrics.Expand()
+	budget.Visit(1)
 	for _, recipe := range recipes {
-		nodeCount += 2
+		metrics.Consider()
+		budget.Visit(2)
 
-		recipeDataMutex.RLock()
-		recipe0Tier := RecipeData[recipe[0]].Tier
-		recipe1Tier := RecipeData[recipe[1]].Tier
-		elementTier := RecipeData[element].Tier
-		recipeDataMutex.RUnlock()
+		recipe0Tier := g.Tier(recipe[0])
+		recipe1Tier := g.Tier(recipe[1])
 
 		if recipe0Tier < elementTier && recipe1Tier < elementTier {
 			currentState := make(map[string][]string)
@@ -161,22 +127,26 @@ func SearchDFS(element string, maxRecipe int) ([]map[string][]string, float64, i
 			// Membuat stack untuk elemen-elemen diproses
 			stack := list.New()
 
-			recipeDataMutex.RLock()
 			// Menambah elemen untuk di proses dengan urutan terbalik sehingga elemen pertama diproses duluan
-			if RecipeData[recipe[1]].Tier > 0 {
+			if !opts.IsLeaf(g, recipe[1]) {
 				stack.PushFront(recipe[1])
 			}
-			if RecipeData[recipe[0]].Tier > 0 {
+			if !opts.IsLeaf(g, recipe[0]) {
 				stack.PushFront(recipe[0])
 			}
-			recipeDataMutex.RUnlock()
 
+			if !budget.Reserve(stateSize(currentState, stack)) {
+				budget.Prune(1)
+				continue
+			}
 			state := map[string]interface{}{
 				"recipeMap": currentState,
 				"stack":     stack,
 			}
 
 			recipeStack.PushFront(state)
+			metrics.Generate(recipeStack.Len())
+			progress(model.Event{Type: model.EventEnqueue, Element: element, Recipe: recipe, Frontier: recipeStack.Len()})
 		}
 	}
 
@@ -184,6 +154,10 @@ func SearchDFS(element string, maxRecipe int) ([]map[string][]string, float64, i
 	resultChan := make(chan map[string][]string)
 
 	numWorkers := runtime.NumCPU()
+	if opts.Deterministic {
+		// Satu worker memproses stack sesuai urutan sehingga hasilnya selalu sama
+		numWorkers = 1
+	}
 	var wg sync.WaitGroup
 
 	var doneMutex sync.Mutex
@@ -222,21 +196,37 @@ func SearchDFS(element string, maxRecipe int) ([]map[string][]string, float64, i
 	seenRecipes := make(map[string]bool)
 	var seenMutex sync.Mutex
 
+	collectorDone := make(chan struct{})
 	go func() {
+		defer close(collectorDone)
 		for r := range resultChan {
-			serialized := serializeRecipe(r)
+			serialized := model.Fingerprint(r)
 
+			isNew := false
 			seenMutex.Lock()
-			if !seenRecipes[serialized] {
+			if seenRecipes[serialized] {
+				metrics.Duplicate()
+			} else {
 				seenRecipes[serialized] = true
 				resultMutex.Lock()
+				if len(result) >= maxRecipe {
+					// Worker yang sudah terlanjur mengirim sebelum done ditutup
+					resultMutex.Unlock()
+					seenMutex.Unlock()
+					continue
+				}
 				result = append(result, r)
+				isNew = true
 				if len(result) >= maxRecipe {
 					safeCloseDone()
 				}
 				resultMutex.Unlock()
 			}
 			seenMutex.Unlock()
+
+			if isNew && opts.OnRecipe != nil {
+				opts.OnRecipe(r)
+			}
 		}
 	}()
 
@@ -268,14 +258,16 @@ func SearchDFS(element string, maxRecipe int) ([]map[string][]string, float64, i
 				// Mengambil dari depan stack
 				currentStateElement := recipeStack.Front()
 				recipeStack.Remove(currentStateElement)
+				frontier := recipeStack.Len()
 				stackMutex.Unlock()
 
 				currentState := currentStateElement.Value.(map[string]interface{})
 				currentRecipeMap := currentState["recipeMap"].(map[string][]string)
 				currentStack := currentState["stack"].(*list.List)
+				budget.Track(-stateSize(currentRecipeMap, currentStack))
 
 				// Dianggap resep jika semua elemen bukan dasar masing-masing ditemukan resepnya juga
-				if currentStack.Len() == 0 && isRecipeComplete(currentRecipeMap) {
+				if currentStack.Len() == 0 && isRecipeComplete(g, opts, currentRecipeMap) {
 					recipeDone := make(map[string][]string)
 					for key, value := range currentRecipeMap {
 						recipeDone[key] = value
@@ -296,16 +288,22 @@ func SearchDFS(element string, maxRecipe int) ([]map[string][]string, float64, i
 				nextElement := currentStack.Front()
 				currentStack.Remove(nextElement)
 				elementToExpand := nextElement.Value.(string)
+				metrics.Expand()
+				progress(model.Event{Type: model.EventExpand, Element: elementToExpand, Frontier: frontier})
 
-				recipeDataMutex.RLock()
-				elementRecipes := RecipeData[elementToExpand].Recipes
-				recipeDataMutex.RUnlock()
+				elementRecipes := opts.Recipes(g, elementToExpand)
 
 				for _, recipe := range elementRecipes {
-					nodeCount += 2
-					if nodeCount%progressLogInterval == 0 && time.Since(lastLogTime) > 2*time.Second {
-						log.Printf("[DFS] Progress - NodeCount: %d, StackSize: %d, ResultCount: %d",
-							nodeCount,
+					considered := metrics.Consider()
+					if !budget.Visit(2) {
+						safeCloseDone()
+						decreaseActive()
+						return
+					}
+					// lastLogTime dibagi antar worker, jadi disimpan sebagai unix nano atomik
+					if considered%progressLogInterval == 0 && time.Since(time.Unix(0, lastLogTime.Load())) > 2*time.Second {
+						log.Printf("[DFS] Progress - RecipesConsidered: %d, StackSize: %d, ResultCount: %d",
+							considered,
 							func() int {
 								stackMutex.Lock()
 								defer stackMutex.Unlock()
@@ -317,7 +315,7 @@ func SearchDFS(element string, maxRecipe int) ([]map[string][]string, float64, i
 								return len(result)
 							}(),
 						)
-						lastLogTime = time.Now()
+						lastLogTime.Store(time.Now().UnixNano())
 					}
 
 					select {
@@ -327,11 +325,9 @@ func SearchDFS(element string, maxRecipe int) ([]map[string][]string, float64, i
 					default:
 					}
 
-					recipeDataMutex.RLock()
-					recipe0Tier := RecipeData[recipe[0]].Tier
-					recipe1Tier := RecipeData[recipe[1]].Tier
-					elementTier := RecipeData[elementToExpand].Tier
-					recipeDataMutex.RUnlock()
+					recipe0Tier := g.Tier(recipe[0])
+					recipe1Tier := g.Tier(recipe[1])
+					elementTier := g.Tier(elementToExpand)
 
 					if recipe0Tier < elementTier && recipe1Tier < elementTier {
 						newRecipeMap := make(map[string][]string)
@@ -346,33 +342,37 @@ func SearchDFS(element string, maxRecipe int) ([]map[string][]string, float64, i
 							newStack.PushBack(el.Value)
 						}
 
-						recipeDataMutex.RLock()
 						// Melakukan proses untuk elemen terdalam lebih dulu, hasil ditambahkan ke depan
 
-						if _, ok := newRecipeMap[recipe[1]]; recipe[0] != recipe[1] && !ok && RecipeData[recipe[1]].Tier > 0 {
+						// Elemen yang sudah menunggu di stack tidak dipush lagi agar tidak diexpand dua kali
+						if _, ok := newRecipeMap[recipe[1]]; recipe[0] != recipe[1] && !ok && !opts.IsLeaf(g, recipe[1]) && !inStack(newStack, recipe[1]) {
 							newStack.PushFront(recipe[1])
 						}
-						if _, ok := newRecipeMap[recipe[0]]; !ok && RecipeData[recipe[0]].Tier > 0 {
+						if _, ok := newRecipeMap[recipe[0]]; !ok && !opts.IsLeaf(g, recipe[0]) && !inStack(newStack, recipe[0]) {
 							newStack.PushFront(recipe[0])
 						}
-						recipeDataMutex.RUnlock()
 
 						// Jika kedua komponen merupakan elemen dasar, dan tidak ada elemen lain di stack
 						// maka merupakan peta resep lengkap
-						recipeDataMutex.RLock()
-						isRecipe0Base := RecipeData[recipe[0]].Tier == 0
-						isRecipe1Base := RecipeData[recipe[1]].Tier == 0
-						recipeDataMutex.RUnlock()
+						isRecipe0Base := opts.IsLeaf(g, recipe[0])
+						isRecipe1Base := opts.IsLeaf(g, recipe[1])
 
-						if isRecipe0Base && isRecipe1Base && newStack.Len() == 0 && isRecipeComplete(newRecipeMap) {
+						if isRecipe0Base && isRecipe1Base && newStack.Len() == 0 && isRecipeComplete(g, opts, newRecipeMap) {
 							select {
 							case <-done:
 								return
 							case resultChan <- newRecipeMap:
 								// Resep lengkap dengan kedua komponen adalah elemen dasar
 							}
+							// Tidak dipush lagi, state ini akan terkirim dua kali saat di-pop
+							continue
 						}
 
+						// State dibuang jika budget memori habis
+						if !budget.Reserve(stateSize(newRecipeMap, newStack)) {
+							budget.Prune(1)
+							continue
+						}
 						newState := map[string]interface{}{
 							"recipeMap": newRecipeMap,
 							"stack":     newStack,
@@ -380,7 +380,11 @@ func SearchDFS(element string, maxRecipe int) ([]map[string][]string, float64, i
 
 						stackMutex.Lock()
 						recipeStack.PushFront(newState) // Push to front for DFS
+						frontier := recipeStack.Len()
 						stackMutex.Unlock()
+						metrics.Generate(frontier)
+
+						progress(model.Event{Type: model.EventEnqueue, Element: elementToExpand, Recipe: recipe, Frontier: frontier})
 					}
 				}
 				decreaseActive()
@@ -388,28 +392,50 @@ func SearchDFS(element string, maxRecipe int) ([]map[string][]string, float64, i
 		}()
 	}
 
+	// Stop every worker as soon as the caller gives up
+	cancelled := false
+	go func() {
+		select {
+		case <-ctx.Done():
+			doneMutex.Lock()
+			cancelled = !isDoneClosed
+			doneMutex.Unlock()
+			safeCloseDone()
+		case <-done:
+		}
+	}()
+
 	wg.Wait()
 	safeCloseDone()
 	close(resultChan)
+	<-collectorDone
+
+	if budget.Hit() == model.BudgetNodes {
+		// State yang tersisa tidak akan pernah diexpand
+		budget.Prune(recipeStack.Len())
+	}
+	if budget.Hit() != "" {
+		log.Printf("DFS ran out of its %s budget, %d states pruned", budget.Hit(), budget.Pruned())
+	}
+
+	doneMutex.Lock()
+	truncated := (cancelled || budget.Hit() != "") && len(result) < maxRecipe
+	doneMutex.Unlock()
+	if cancelled && truncated {
+		log.Printf("DFS stopped early: %v", ctx.Err())
+	}
 
 	duration := time.Since(startTime)
 	log.Printf("DFS took %s", duration)
 
-	return result, float64(duration.Seconds()), nodeCount
-}
-
-// Mengubah peta resep ke bentuk string
-func serializeRecipe(r map[string][]string) string {
-	var sb strings.Builder
-	keys := make([]string, 0, len(r))
-	for k := range r {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	for _, k := range keys {
-		v := r[k]
-		sort.Strings(v)
-		sb.WriteString(k + ":" + strings.Join(v, ",") + ";")
+	stats := metrics.Stats()
+	return model.Result{
+		Recipes:     result,
+		Duration:    duration.Seconds(),
+		VisitedNode: stats.VisitedNodes(),
+		Truncated:   truncated,
+		Budget:      budget.Hit(),
+		Pruned:      budget.Pruned(),
+		Stats:       stats,
 	}
-	return sb.String()
 }
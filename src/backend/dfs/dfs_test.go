@@ -0,0 +1,38 @@
+package dfs
+
+import (
+	"context"
+	"recipe-finder/graph"
+	"recipe-finder/model"
+	"testing"
+)
+
+// Metal is an ingredient of Sword and of Blade, so it is waiting on the
+// stack twice unless DFS notices it is already there
+func swordGraph() *graph.RecipeGraph {
+	return graph.New(map[string]graph.Recipe{
+		"Air":   {Tier: 0},
+		"Earth": {Tier: 0},
+		"Fire":  {Tier: 0},
+		"Metal": {Tier: 1, Recipes: [][]string{{"Earth", "Fire"}, {"Air", "Earth"}}},
+		"Blade": {Tier: 2, Recipes: [][]string{{"Metal", "Air"}}},
+		"Sword": {Tier: 3, Recipes: [][]string{{"Blade", "Metal"}}},
+	})
+}
+
+func TestSharedIngredient(t *testing.T) {
+	for _, deterministic := range []bool{false, true} {
+		result := SearchDFS(context.Background(), swordGraph(), "Sword", 10, model.Options{Deterministic: deterministic})
+		if len(result.Recipes) != 2 {
+			t.Errorf("deterministic %v: found %d recipes, want 2", deterministic, len(result.Recipes))
+		}
+		// Metal yang diexpand dua kali menghasilkan resep yang sama lagi
+		if result.Stats.Duplicates != 0 {
+			t.Errorf("deterministic %v: %d duplicates, want 0", deterministic, result.Stats.Duplicates)
+		}
+		// Sword, Blade dan Metal masing-masing diexpand sekali
+		if result.Stats.Expanded != 3 {
+			t.Errorf("deterministic %v: expanded %d states, want 3", deterministic, result.Stats.Expanded)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"recipe-finder/graph"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultElementLimit = 50
+	maxElementLimit     = 1000
+)
+
+type elementSummary struct {
+	Name     string `json:"name"`
+	Tier     int    `json:"tier"`
+	ImageURL string `json:"imageURL"`
+	Special  bool   `json:"special,omitempty"`
+	Unlock   string `json:"unlock,omitempty"`
+}
+
+type elementListResponse struct {
+	Elements []elementSummary `json:"elements"`
+	Total    int              `json:"total"`
+	Offset   int              `json:"offset"`
+	Limit    int              `json:"limit"`
+}
+
+type elementDetail struct {
+	elementSummary
+	Recipes [][]string `json:"recipes"`
+	UsedIn  []string   `json:"usedIn"`
+}
+
+func summarize(g *graph.RecipeGraph, name string) elementSummary {
+	return elementSummary{
+		Name:     name,
+		Tier:     g.Tier(name),
+		ImageURL: "/images/" + graph.ImageName(name),
+		Special:  g.IsSpecial(name),
+		Unlock:   g.Unlock(name),
+	}
+}
+
+// queryInt reads an optional integer query parameter
+func queryInt(r *http.Request, key string, fallback int) (int, bool) {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return fallback, true
+	}
+	value, err := strconv.Atoi(raw)
+	return value, err == nil
+}
+
+// filterElements lists the elements of tier, or of every tier when tier is
+// negative, whose name starts with prefix regardless of case. They are
+// ordered by tier and then by name.
+func filterElements(g *graph.RecipeGraph, tier int, prefix string) []elementSummary {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	matches := []elementSummary{}
+	for t := 0; t <= g.MaxTier(); t++ {
+		if tier >= 0 && t != tier {
+			continue
+		}
+		for _, name := range g.ElementsInTier(t) {
+			if strings.HasPrefix(strings.ToLower(name), prefix) {
+				matches = append(matches, summarize(g, name))
+			}
+		}
+	}
+	return matches
+}
+
+// handleElements lists the elements ordered by tier then name. The list can
+// be filtered with tier and a case-insensitive name prefix, and paged with
+// offset and limit.
+func (s *server) handleElements(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	tier, ok := queryInt(r, "tier", -1)
+	if !ok {
+		writeError(w, newAPIError(http.StatusBadRequest, codeInvalidRequest, "tier must be an integer"))
+		return
+	}
+	offset, ok := queryInt(r, "offset", 0)
+	if !ok || offset < 0 {
+		writeError(w, newAPIError(http.StatusBadRequest, codeInvalidRequest, "offset must be a non-negative integer"))
+		return
+	}
+	limit, ok := queryInt(r, "limit", defaultElementLimit)
+	if !ok || limit < 1 || limit > maxElementLimit {
+		writeError(w, newAPIError(http.StatusBadRequest, codeInvalidLimit, "limit must be between 1 and %d", maxElementLimit))
+		return
+	}
+	prefix := r.URL.Query().Get("prefix")
+
+	dataset := r.URL.Query().Get("dataset")
+	g, apiErr := s.graphFor(&dataset)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	matches := filterElements(g, tier, prefix)
+
+	response := elementListResponse{
+		Elements: []elementSummary{},
+		Total:    len(matches),
+		Offset:   offset,
+		Limit:    limit,
+	}
+	if offset < len(matches) {
+		response.Elements = matches[offset:min(offset+limit, len(matches))]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleElement returns one element with its direct recipes and the
+// elements it is an ingredient of
+func (s *server) handleElement(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	name := strings.TrimSpace(r.PathValue("name"))
+	dataset := r.URL.Query().Get("dataset")
+	g, apiErr := s.graphFor(&dataset)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	if !g.Has(name) {
+		err := newAPIError(http.StatusNotFound, codeUnknownElement, "unknown element %q", name)
+		err.Suggestions = g.Suggest(name, 5)
+		writeError(w, err)
+		return
+	}
+
+	detail := elementDetail{
+		elementSummary: summarize(g, name),
+		Recipes:        g.Recipes(name),
+		UsedIn:         g.UsedIn(name),
+	}
+	if detail.Recipes == nil {
+		detail.Recipes = [][]string{}
+	}
+	if detail.UsedIn == nil {
+		detail.UsedIn = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(detail)
+}
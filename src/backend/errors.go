@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"recipe-finder/graph"
+	"recipe-finder/model"
+	"strings"
+)
+
+// Error codes returned in apiError.Code
+const (
+	codeInvalidRequest   = "INVALID_REQUEST"
+	codeMethodNotAllowed = "METHOD_NOT_ALLOWED"
+	codeUnknownElement   = "UNKNOWN_ELEMENT"
+	codeUnknownAlgorithm = "UNKNOWN_ALGORITHM"
+	codeInvalidLimit     = "INVALID_LIMIT"
+	codeTimeout          = "TIMEOUT"
+	codeUnauthorized     = "UNAUTHORIZED"
+	codeInvalidData      = "INVALID_DATA"
+	codeUnknownDataset   = "UNKNOWN_DATASET"
+	codeUnknownCursor    = "UNKNOWN_CURSOR"
+)
+
+// apiError is the body of every failed request, wrapped in {"error": ...}
+type apiError struct {
+	Status      int      `json:"-"`
+	Code        string   `json:"code"`
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions,omitempty"`
+	// Details lists every individual problem when there is more than one
+	Details []string `json:"details,omitempty"`
+}
+
+func newAPIError(status int, code string, format string, args ...any) *apiError {
+	return &apiError{Status: status, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, err *apiError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(err.Status)
+	json.NewEncoder(w).Encode(struct {
+		Error *apiError `json:"error"`
+	}{err})
+}
+
+// methodNotAllowed answers requests with the wrong HTTP method
+func methodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeError(w, newAPIError(http.StatusMethodNotAllowed, codeMethodNotAllowed, "Only %s allowed", allowed))
+}
+
+// validateRequest normalizes req and checks it against the graph
+func validateRequest(g *graph.RecipeGraph, req *RecipeRequest) *apiError {
+	req.Element = strings.TrimSpace(req.Element)
+	if req.Element == "" {
+		return newAPIError(http.StatusBadRequest, codeUnknownElement, "element is required")
+	}
+	if !g.Has(req.Element) {
+		err := newAPIError(http.StatusNotFound, codeUnknownElement, "unknown element %q", req.Element)
+		err.Suggestions = g.Suggest(req.Element, 5)
+		return err
+	}
+
+	for i, name := range req.Owned {
+		name = strings.TrimSpace(name)
+		if !g.Has(name) {
+			err := newAPIError(http.StatusNotFound, codeUnknownElement, "unknown owned element %q", name)
+			err.Suggestions = g.Suggest(name, 5)
+			return err
+		}
+		req.Owned[i] = name
+	}
+
+	if req.MaxRecipe <= 0 {
+		return newAPIError(http.StatusBadRequest, codeInvalidLimit, "maxRecipe must be at least 1, got %d", req.MaxRecipe)
+	}
+	if req.MaxNodes < 0 || req.MaxMemoryMB < 0 {
+		return newAPIError(http.StatusBadRequest, codeInvalidLimit, "maxNodes and maxMemoryMB must not be negative")
+	}
+
+	switch req.Format {
+	case "":
+		req.Format = formatFlat
+	case formatFlat, formatTree:
+	default:
+		return newAPIError(http.StatusBadRequest, codeInvalidRequest, "unknown format %q, expected \"flat\" or \"tree\"", req.Format)
+	}
+
+	if req.Resumable && (req.Mode != "" || req.Algorithm != "bfs") {
+		return newAPIError(http.StatusBadRequest, codeInvalidRequest, "resumable searches need algorithm \"bfs\" without a mode")
+	}
+
+	switch req.Mode {
+	case "":
+	case "shortest":
+		// Algorithm tidak dipakai pada mode shortest
+		return nil
+	default:
+		return newAPIError(http.StatusBadRequest, codeInvalidRequest, "unknown mode %q, expected \"shortest\" or empty", req.Mode)
+	}
+
+	switch req.Algorithm {
+	case "bfs", "dfs", "bidirectional":
+	default:
+		return newAPIError(http.StatusBadRequest, codeUnknownAlgorithm, "unknown algorithm %q, expected \"bfs\", \"dfs\" or \"bidirectional\"", req.Algorithm)
+	}
+	return nil
+}
+
+// timeoutError reports a search that hit its deadline without finding
+// anything, partial results are returned normally with truncated set
+func timeoutError(ctx context.Context, req RecipeRequest, result model.Result) *apiError {
+	if !result.Truncated || len(result.Recipes) > 0 || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil
+	}
+	return newAPIError(http.StatusGatewayTimeout, codeTimeout, "no recipe for %q found before the search timed out", req.Element)
+}
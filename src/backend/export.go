@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"recipe-finder/export"
+	"recipe-finder/model"
+)
+
+// exportTypes maps the export format to its content type
+var exportTypes = map[string]string{
+	"dot":     "text/vnd.graphviz; charset=utf-8",
+	"mermaid": "text/plain; charset=utf-8",
+	"svg":     "image/svg+xml",
+}
+
+// handleRecipeExport runs a search like /api/recipe/stream and renders every
+// recipe found as a single DOT, Mermaid or SVG document
+func (s *server) handleRecipeExport(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	req, err := recipeRequestFromQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// format di sini adalah format export, bukan bentuk hasil pencarian
+	exportFormat := req.Format
+	contentType, ok := exportTypes[exportFormat]
+	if !ok {
+		writeError(w, newAPIError(http.StatusBadRequest, codeInvalidRequest, "unknown format %q, expected \"dot\", \"mermaid\" or \"svg\"", exportFormat))
+		return
+	}
+	req.Format = ""
+	g, apiErr := s.graphFor(&req.Dataset)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	if err := validateRequest(g, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ctx, cancel := s.searchContext(r)
+	defer cancel()
+
+	result, _ := s.explore(ctx, g, req, model.Options{})
+	if err := timeoutError(ctx, req, result); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	trees := make([]*model.TreeNode, len(result.Recipes))
+	for i, recipe := range result.Recipes {
+		trees[i] = model.BuildTree(g, req.Element, recipe)
+	}
+	var body string
+	switch exportFormat {
+	case "dot":
+		body = export.DOT(trees)
+	case "mermaid":
+		body = export.Mermaid(trees)
+	case "svg":
+		body = export.SVG(trees, s.imageDir)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write([]byte(body))
+}
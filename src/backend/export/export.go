@@ -0,0 +1,86 @@
+// Package export renders recipe trees as text and images that can be
+// pasted into documentation.
+package export
+
+import (
+	"fmt"
+	"recipe-finder/model"
+	"strings"
+)
+
+// DOT writes every tree as its own cluster of a single Graphviz digraph.
+// Edges point from an element to its ingredients, special elements are
+// drawn dashed.
+func DOT(trees []*model.TreeNode) string {
+	var sb strings.Builder
+	sb.WriteString("digraph recipes {\n")
+	sb.WriteString("  rankdir=TB;\n")
+	sb.WriteString("  node [shape=box, style=rounded];\n")
+	for i, tree := range trees {
+		fmt.Fprintf(&sb, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&sb, "    label=%s;\n", dotQuote(fmt.Sprintf("Recipe %d", i+1)))
+		walk(tree, func(node, parent *model.TreeNode) {
+			id := nodeID(i, node)
+			if node.Special {
+				fmt.Fprintf(&sb, "    %s [label=%s, style=\"rounded,dashed\", tooltip=%s];\n", id, dotQuote(node.Name), dotQuote(node.Unlock))
+			} else {
+				fmt.Fprintf(&sb, "    %s [label=%s];\n", id, dotQuote(node.Name))
+			}
+			if parent != nil {
+				fmt.Fprintf(&sb, "    %s -> %s;\n", nodeID(i, parent), id)
+			}
+		})
+		sb.WriteString("  }\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid writes every tree as a subgraph of a single top-down flowchart,
+// special elements get the special class
+func Mermaid(trees []*model.TreeNode) string {
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	sb.WriteString("  classDef special stroke-dasharray: 5 5\n")
+	for i, tree := range trees {
+		fmt.Fprintf(&sb, "  subgraph recipe_%d [\"Recipe %d\"]\n", i, i+1)
+		walk(tree, func(node, parent *model.TreeNode) {
+			id := nodeID(i, node)
+			if node.Special {
+				fmt.Fprintf(&sb, "    %s[\"%s\"]:::special\n", id, mermaidEscape(node.Name))
+			} else {
+				fmt.Fprintf(&sb, "    %s[\"%s\"]\n", id, mermaidEscape(node.Name))
+			}
+			if parent != nil {
+				fmt.Fprintf(&sb, "    %s --> %s\n", nodeID(i, parent), id)
+			}
+		})
+		sb.WriteString("  end\n")
+	}
+	return sb.String()
+}
+
+// walk visits every node in pre-order together with its parent
+func walk(node *model.TreeNode, visit func(node, parent *model.TreeNode)) {
+	var rec func(node, parent *model.TreeNode)
+	rec = func(node, parent *model.TreeNode) {
+		visit(node, parent)
+		for _, child := range node.Children {
+			rec(child, node)
+		}
+	}
+	rec(node, nil)
+}
+
+// nodeID keeps node ids unique across all trees of one export
+func nodeID(tree int, node *model.TreeNode) string {
+	return fmt.Sprintf("r%d_n%d", tree, node.ID)
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
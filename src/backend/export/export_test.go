@@ -0,0 +1,174 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"recipe-finder/graph"
+	"recipe-finder/model"
+	"strings"
+	"testing"
+)
+
+// trees returns two recipes of Cloud, the second one using the special
+// element Time
+func trees() []*model.TreeNode {
+	g := graph.New(map[string]graph.Recipe{
+		"Air":   {Tier: 0},
+		"Water": {Tier: 0},
+		"Time":  {Tier: 0, Special: true, Unlock: `Discover "100" elements`},
+		"Steam": {Tier: 1, Recipes: [][]string{{"Air", "Water"}}},
+		"Cloud": {Tier: 2, Recipes: [][]string{{"Air", "Steam"}, {"Steam", "Time"}}},
+	})
+	return []*model.TreeNode{
+		model.BuildTree(g, "Cloud", map[string][]string{"Cloud": {"Air", "Steam"}, "Steam": {"Air", "Water"}}),
+		model.BuildTree(g, "Cloud", map[string][]string{"Cloud": {"Steam", "Time"}, "Steam": {"Air", "Water"}}),
+	}
+}
+
+func TestDOT(t *testing.T) {
+	want := `digraph recipes {
+  rankdir=TB;
+  node [shape=box, style=rounded];
+  subgraph cluster_0 {
+    label="Recipe 1";
+    r0_n0 [label="Cloud"];
+    r0_n1 [label="Air"];
+    r0_n0 -> r0_n1;
+    r0_n2 [label="Steam"];
+    r0_n0 -> r0_n2;
+    r0_n3 [label="Air"];
+    r0_n2 -> r0_n3;
+    r0_n4 [label="Water"];
+    r0_n2 -> r0_n4;
+  }
+  subgraph cluster_1 {
+    label="Recipe 2";
+    r1_n0 [label="Cloud"];
+    r1_n1 [label="Steam"];
+    r1_n0 -> r1_n1;
+    r1_n2 [label="Air"];
+    r1_n1 -> r1_n2;
+    r1_n3 [label="Water"];
+    r1_n1 -> r1_n3;
+    r1_n4 [label="Time", style="rounded,dashed", tooltip="Discover \"100\" elements"];
+    r1_n0 -> r1_n4;
+  }
+}
+`
+	if got := DOT(trees()); got != want {
+		t.Errorf("DOT =\n%s\nwant\n%s", got, want)
+	}
+	if got := DOT(nil); got != "digraph recipes {\n  rankdir=TB;\n  node [shape=box, style=rounded];\n}\n" {
+		t.Errorf("DOT(nil) = %q", got)
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	want := `flowchart TD
+  classDef special stroke-dasharray: 5 5
+  subgraph recipe_0 ["Recipe 1"]
+    r0_n0["Cloud"]
+    r0_n1["Air"]
+    r0_n0 --> r0_n1
+    r0_n2["Steam"]
+    r0_n0 --> r0_n2
+    r0_n3["Air"]
+    r0_n2 --> r0_n3
+    r0_n4["Water"]
+    r0_n2 --> r0_n4
+  end
+  subgraph recipe_1 ["Recipe 2"]
+    r1_n0["Cloud"]
+    r1_n1["Steam"]
+    r1_n0 --> r1_n1
+    r1_n2["Air"]
+    r1_n1 --> r1_n2
+    r1_n3["Water"]
+    r1_n1 --> r1_n3
+    r1_n4["Time"]:::special
+    r1_n0 --> r1_n4
+  end
+`
+	if got := Mermaid(trees()); got != want {
+		t.Errorf("Mermaid =\n%s\nwant\n%s", got, want)
+	}
+	if got := mermaidEscape(`Say "hi"`); got != "Say #quot;hi#quot;" {
+		t.Errorf("mermaidEscape = %q", got)
+	}
+}
+
+func TestText(t *testing.T) {
+	want := "Recipe 1\n" +
+		"Cloud\n" +
+		"|-- Air\n" +
+		"`-- Steam\n" +
+		"    |-- Air\n" +
+		"    `-- Water\n" +
+		"\n" +
+		"Recipe 2\n" +
+		"Cloud\n" +
+		"|-- Steam\n" +
+		"|   |-- Air\n" +
+		"|   `-- Water\n" +
+		"`-- Time (special: Discover \"100\" elements)\n"
+	if got := Text(trees()); got != want {
+		t.Errorf("Text =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, graph.ImageName("Air")), []byte("<svg/>"), 0o644)
+
+	for _, imageDir := range []string{"", dir} {
+		got := SVG(trees(), imageDir)
+		if !strings.HasPrefix(got, `<svg xmlns="http://www.w3.org/2000/svg"`) || !strings.HasSuffix(got, "</svg>\n") {
+			t.Errorf("%q: not a complete SVG document", imageDir)
+		}
+		// Sepuluh node dengan delapan garis, Time digambar putus-putus
+		if n := strings.Count(got, "<g>"); n != 10 {
+			t.Errorf("%q: %d nodes, want 10", imageDir, n)
+		}
+		if n := strings.Count(got, "<line"); n != 8 {
+			t.Errorf("%q: %d edges, want 8", imageDir, n)
+		}
+		if n := strings.Count(got, "stroke-dasharray"); n != 1 {
+			t.Errorf("%q: %d dashed nodes, want 1", imageDir, n)
+		}
+		if !strings.Contains(got, "<title>Time (special: Discover &#34;100&#34; elements)</title>") {
+			t.Errorf("%q: unlock condition of Time missing or not escaped", imageDir)
+		}
+
+		// Hanya Air yang punya ikon, dipakai di tiga node
+		icons := strings.Count(got, `<image href="data:image/svg+xml;base64,PHN2Zy8+"`)
+		if want := map[string]int{"": 0, dir: 3}[imageDir]; icons != want {
+			t.Errorf("%q: %d embedded icons, want %d", imageDir, icons, want)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	tree := trees()[0]
+	positions, columns, depth := layout(tree, 0, 0)
+	if columns != 3 || depth != 2 {
+		t.Fatalf("layout used %d columns and depth %d, want 3 and 2", columns, depth)
+	}
+
+	// Setiap leaf punya kolomnya sendiri dan parent berada di tengah anak-anaknya
+	air, steam, steamAir, water := tree.Children[0], tree.Children[1], tree.Children[1].Children[0], tree.Children[1].Children[1]
+	tests := []struct {
+		node *model.TreeNode
+		want point
+	}{
+		{air, point{slotWidth / 2, rowHeight}},
+		{steamAir, point{slotWidth * 3 / 2, 2 * rowHeight}},
+		{water, point{slotWidth * 5 / 2, 2 * rowHeight}},
+		{steam, point{slotWidth * 2, rowHeight}},
+		{tree, point{(slotWidth/2.0 + slotWidth*2) / 2, 0}},
+	}
+	for _, tt := range tests {
+		if got := positions[tt.node]; got != tt.want {
+			t.Errorf("%s at %v, want %v", tt.node.Name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package export
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"recipe-finder/graph"
+	"recipe-finder/model"
+	"strings"
+)
+
+// Layout of the rendered SVG in pixels
+const (
+	slotWidth   = 110
+	rowHeight   = 90
+	iconSize    = 40
+	titleHeight = 30
+	margin      = 20
+)
+
+type point struct {
+	x, y float64
+}
+
+// SVG draws the trees one below the other. When imageDir is set, the icon
+// of each element found there is embedded into the image, elements without
+// an icon are drawn as a plain box.
+func SVG(trees []*model.TreeNode, imageDir string) string {
+	icons := make(map[string]string)
+	icon := func(name string) string {
+		if imageDir == "" {
+			return ""
+		}
+		uri, ok := icons[name]
+		if !ok {
+			if data, err := os.ReadFile(filepath.Join(imageDir, graph.ImageName(name))); err == nil {
+				uri = "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(data)
+			}
+			icons[name] = uri
+		}
+		return uri
+	}
+
+	var body strings.Builder
+	width, top := 0, margin
+	for i, tree := range trees {
+		positions, leaves, depth := layout(tree, float64(margin), float64(top+titleHeight))
+		width = max(width, leaves*slotWidth)
+
+		fmt.Fprintf(&body, `<text x="%d" y="%d" font-weight="bold">Recipe %d</text>`+"\n", margin, top+titleHeight/2, i+1)
+		walk(tree, func(node, parent *model.TreeNode) {
+			if parent != nil {
+				from, to := positions[parent], positions[node]
+				fmt.Fprintf(&body, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#999"/>`+"\n",
+					from.x, from.y+iconSize, to.x, to.y)
+			}
+		})
+		walk(tree, func(node, _ *model.TreeNode) {
+			writeNode(&body, node, positions[node], icon(node.Name))
+		})
+
+		top += titleHeight + (depth+1)*rowHeight
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n",
+		width+2*margin, top+margin)
+	sb.WriteString(body.String())
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+func writeNode(sb *strings.Builder, node *model.TreeNode, at point, iconURI string) {
+	name := html.EscapeString(node.Name)
+	if node.Special {
+		fmt.Fprintf(sb, `<g><title>%s (special: %s)</title>`, name, html.EscapeString(node.Unlock))
+	} else {
+		fmt.Fprintf(sb, `<g><title>%s (tier %d)</title>`, name, node.Tier)
+	}
+	if node.Special {
+		// Garis putus-putus di belakang ikon menandai elemen spesial
+		fmt.Fprintf(sb, `<rect x="%.1f" y="%.1f" width="%d" height="%d" rx="6" fill="none" stroke="#999" stroke-dasharray="4 3"/>`,
+			at.x-iconSize/2-3, at.y-3, iconSize+6, iconSize+6)
+	}
+	if iconURI != "" {
+		fmt.Fprintf(sb, `<image href="%s" x="%.1f" y="%.1f" width="%d" height="%d"/>`,
+			iconURI, at.x-iconSize/2, at.y, iconSize, iconSize)
+	} else {
+		fmt.Fprintf(sb, `<rect x="%.1f" y="%.1f" width="%d" height="%d" rx="6" fill="#f3f4f6" stroke="#999"/>`,
+			at.x-iconSize/2, at.y, iconSize, iconSize)
+	}
+	fmt.Fprintf(sb, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text></g>`+"\n",
+		at.x, at.y+iconSize+14, name)
+}
+
+// layout places every leaf in its own column and centers each parent above
+// its children. It returns the positions, the number of columns used and
+// the depth of the tree.
+func layout(tree *model.TreeNode, left, top float64) (map[*model.TreeNode]point, int, int) {
+	positions := make(map[*model.TreeNode]point)
+	column, depth := 0, 0
+	var place func(node *model.TreeNode) float64
+	place = func(node *model.TreeNode) float64 {
+		depth = max(depth, node.Depth)
+		var x float64
+		if len(node.Children) == 0 {
+			x = left + float64(column*slotWidth) + slotWidth/2
+			column++
+		} else {
+			first := place(node.Children[0])
+			last := first
+			for _, child := range node.Children[1:] {
+				last = place(child)
+			}
+			x = (first + last) / 2
+		}
+		positions[node] = point{x: x, y: top + float64(node.Depth*rowHeight)}
+		return x
+	}
+	place(tree)
+	return positions, column, depth
+}
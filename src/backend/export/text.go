@@ -0,0 +1,45 @@
+package export
+
+import (
+	"fmt"
+	"recipe-finder/model"
+	"strings"
+)
+
+// Text draws every tree as indented ASCII art for terminals, separated by
+// a blank line
+func Text(trees []*model.TreeNode) string {
+	var sb strings.Builder
+	for i, tree := range trees {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "Recipe %d\n", i+1)
+		sb.WriteString(tree.Name + "\n")
+		writeChildren(&sb, tree, "")
+	}
+	return sb.String()
+}
+
+func writeChildren(sb *strings.Builder, node *model.TreeNode, indent string) {
+	for i, child := range node.Children {
+		branch, next := "|-- ", "|   "
+		if i == len(node.Children)-1 {
+			branch, next = "`-- ", "    "
+		}
+		sb.WriteString(indent + branch + label(child) + "\n")
+		writeChildren(sb, child, indent+next)
+	}
+}
+
+// label is the text shown for a node, special elements carry their unlock
+// condition since they cannot be combined
+func label(node *model.TreeNode) string {
+	if !node.Special {
+		return node.Name
+	}
+	if node.Unlock == "" {
+		return node.Name + " (special)"
+	}
+	return node.Name + " (special: " + node.Unlock + ")"
+}
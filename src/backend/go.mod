@@ -4,10 +4,8 @@ go 1.24.3
 
 require (
 	github.com/PuerkitoBio/goquery v1.10.3
+	golang.org/x/net v0.39.0
 	golang.org/x/sync v0.10.0
 )
 
-require (
-	github.com/andybalholm/cascadia v1.3.3 // indirect
-	golang.org/x/net v0.39.0 // indirect
-)
+require github.com/andybalholm/cascadia v1.3.3 // indirect
@@ -0,0 +1,212 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Recipe is a single element entry as stored in recipes_complete.json
+type Recipe struct {
+	Tier    int        `json:"tier"`
+	Recipes [][]string `json:"recipes"`
+	// Special elements are not made by combining, they are unlocked by
+	// the condition in Unlock. They are always tier 0 without recipes.
+	Special bool   `json:"special,omitempty"`
+	Unlock  string `json:"unlock,omitempty"`
+}
+
+// RecipeGraph is an indexed, read-only view of the recipe data.
+// Nothing is modified after New returns, so a graph can be shared
+// between any number of searches without locking.
+type RecipeGraph struct {
+	elements map[string]Recipe
+	names    []string
+	ids      map[string]int
+	tiers    [][]string
+	usedIn   map[string][]string
+	// standard holds the recipes without any special ingredient, only for
+	// elements where that differs from all recipes
+	standard map[string][][]string
+}
+
+// New builds a graph from decoded recipe data. The data is copied,
+// later changes to the given map do not affect the graph.
+func New(data map[string]Recipe) *RecipeGraph {
+	g := &RecipeGraph{
+		elements: make(map[string]Recipe, len(data)),
+		names:    make([]string, 0, len(data)),
+		ids:      make(map[string]int, len(data)),
+		usedIn:   make(map[string][]string),
+		standard: make(map[string][][]string),
+	}
+
+	maxTier := 0
+	for name, el := range data {
+		recipes := make([][]string, 0, len(el.Recipes))
+		for _, recipe := range el.Recipes {
+			recipes = append(recipes, append([]string(nil), recipe...))
+		}
+		g.elements[name] = Recipe{Tier: el.Tier, Recipes: recipes, Special: el.Special, Unlock: el.Unlock}
+		g.names = append(g.names, name)
+		if el.Tier > maxTier {
+			maxTier = el.Tier
+		}
+	}
+	sort.Strings(g.names)
+	for id, name := range g.names {
+		g.ids[name] = id
+	}
+
+	// Index per tier dan reverse index "used in", urutan mengikuti nama
+	g.tiers = make([][]string, maxTier+1)
+	for _, name := range g.names {
+		el := g.elements[name]
+		if el.Tier >= 0 {
+			g.tiers[el.Tier] = append(g.tiers[el.Tier], name)
+		}
+		for _, recipe := range el.Recipes {
+			for i, ing := range recipe {
+				if i > 0 && recipe[i-1] == ing {
+					continue
+				}
+				uses := g.usedIn[ing]
+				if len(uses) == 0 || uses[len(uses)-1] != name {
+					g.usedIn[ing] = append(uses, name)
+				}
+			}
+		}
+	}
+
+	for _, name := range g.names {
+		recipes := g.elements[name].Recipes
+		var standard [][]string
+		for _, recipe := range recipes {
+			if !g.usesSpecial(recipe) {
+				standard = append(standard, recipe)
+			}
+		}
+		if len(standard) != len(recipes) {
+			g.standard[name] = standard
+		}
+	}
+
+	return g
+}
+
+func (g *RecipeGraph) usesSpecial(recipe []string) bool {
+	for _, ing := range recipe {
+		if g.elements[ing].Special {
+			return true
+		}
+	}
+	return false
+}
+
+// Read decodes recipe JSON from r and builds a graph from it
+func Read(r io.Reader) (*RecipeGraph, error) {
+	data := make(map[string]Recipe)
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decoding recipes: %w", err)
+	}
+	return New(data), nil
+}
+
+// Load reads the recipe JSON file at path
+func Load(path string) (*RecipeGraph, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Read(file)
+}
+
+// Has reports whether the element exists in the graph
+func (g *RecipeGraph) Has(name string) bool {
+	_, ok := g.elements[name]
+	return ok
+}
+
+// Tier returns the tier of an element, unknown elements are tier 0
+func (g *RecipeGraph) Tier(name string) int {
+	return g.elements[name].Tier
+}
+
+// Recipes returns every ingredient pair that makes the element.
+// The returned slices are shared and must not be modified.
+func (g *RecipeGraph) Recipes(name string) [][]string {
+	return g.elements[name].Recipes
+}
+
+// StandardRecipes returns the recipes of the element that do not need any
+// special element. The returned slices are shared and must not be modified.
+func (g *RecipeGraph) StandardRecipes(name string) [][]string {
+	if standard, ok := g.standard[name]; ok {
+		return standard
+	}
+	return g.elements[name].Recipes
+}
+
+// IsSpecial reports whether the element is unlocked instead of combined
+func (g *RecipeGraph) IsSpecial(name string) bool {
+	return g.elements[name].Special
+}
+
+// Unlock returns the unlock condition of a special element
+func (g *RecipeGraph) Unlock(name string) string {
+	return g.elements[name].Unlock
+}
+
+// UsedIn returns the elements that have name as a direct ingredient, sorted by name
+func (g *RecipeGraph) UsedIn(name string) []string {
+	return g.usedIn[name]
+}
+
+// Names returns every element name in sorted order
+func (g *RecipeGraph) Names() []string {
+	return g.names
+}
+
+// ID returns the position of the element in Names, searches use it as a
+// compact handle. Unknown elements return -1.
+func (g *RecipeGraph) ID(name string) int {
+	id, ok := g.ids[name]
+	if !ok {
+		return -1
+	}
+	return id
+}
+
+// Name returns the element with the given ID
+func (g *RecipeGraph) Name(id int) string {
+	return g.names[id]
+}
+
+// ElementsInTier returns the names of the elements in the given tier, sorted by name
+func (g *RecipeGraph) ElementsInTier(tier int) []string {
+	if tier < 0 || tier >= len(g.tiers) {
+		return nil
+	}
+	return g.tiers[tier]
+}
+
+// MaxTier returns the highest tier present in the graph
+func (g *RecipeGraph) MaxTier() int {
+	return len(g.tiers) - 1
+}
+
+// Len returns the number of elements in the graph
+func (g *RecipeGraph) Len() int {
+	return len(g.elements)
+}
+
+// ImageName returns the file name of an element's icon, as stored in the
+// frontend's public/images directory
+func ImageName(name string) string {
+	return strings.ReplaceAll(name, " ", "_") + ".svg"
+}
@@ -0,0 +1,21 @@
+// Package graphtest provides small recipe graphs for tests.
+package graphtest
+
+import "recipe-finder/graph"
+
+// Small returns a graph with four base elements where Rain has six
+// recipes: two through Cloud and four through Steam and Stone
+func Small() *graph.RecipeGraph {
+	return graph.New(map[string]graph.Recipe{
+		"Air":   {Tier: 0},
+		"Earth": {Tier: 0},
+		"Fire":  {Tier: 0},
+		"Water": {Tier: 0},
+		"Lava":  {Tier: 1, Recipes: [][]string{{"Earth", "Fire"}}},
+		"Mud":   {Tier: 1, Recipes: [][]string{{"Earth", "Water"}}},
+		"Steam": {Tier: 1, Recipes: [][]string{{"Air", "Water"}, {"Fire", "Water"}}},
+		"Cloud": {Tier: 2, Recipes: [][]string{{"Air", "Steam"}}},
+		"Stone": {Tier: 2, Recipes: [][]string{{"Air", "Lava"}, {"Lava", "Mud"}}},
+		"Rain":  {Tier: 3, Recipes: [][]string{{"Cloud", "Water"}, {"Steam", "Stone"}}},
+	})
+}
@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggest returns up to n element names that look like name, closest first.
+// Names that start with name count as close even when they are much longer.
+func (g *RecipeGraph) Suggest(name string, n int) []string {
+	query := strings.ToLower(strings.TrimSpace(name))
+	if query == "" || n <= 0 {
+		return nil
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	limit := max(2, len([]rune(query))/3)
+
+	var candidates []candidate
+	for _, element := range g.names {
+		lower := strings.ToLower(element)
+		distance := levenshtein(query, lower)
+		if distance > limit && strings.HasPrefix(lower, query) {
+			distance = limit
+		}
+		if distance <= limit {
+			candidates = append(candidates, candidate{name: element, distance: distance})
+		}
+	}
+
+	// names sudah terurut, jadi urutan untuk jarak yang sama tetap alfabetis
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < n; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+	return suggestions
+}
+
+// levenshtein counts the single character edits needed to turn a into b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"water", "water", 0},
+		{"watr", "water", 1},
+		{"wster", "water", 1},
+		{"waetr", "water", 2},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"čaj", "caj", 1},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := levenshtein(tt.b, tt.a); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	g := New(map[string]Recipe{
+		"Air":        {Tier: 0},
+		"Fire":       {Tier: 0},
+		"Water":      {Tier: 0},
+		"Wave":       {Tier: 1, Recipes: [][]string{{"Air", "Water"}}},
+		"Waterfall":  {Tier: 1, Recipes: [][]string{{"Water", "Water"}}},
+		"Firework":   {Tier: 1, Recipes: [][]string{{"Fire", "Air"}}},
+		"Fireplace":  {Tier: 1, Recipes: [][]string{{"Fire", "Water"}}},
+		"Watermelon": {Tier: 1, Recipes: [][]string{{"Water", "Fire"}}},
+	})
+
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{"watr", 5, []string{"Water", "Air", "Wave"}},
+		{"  WATER ", 1, []string{"Water"}},
+		// Nama yang diawali query dianggap sedekat batas jarak, urutannya alfabetis
+		{"water", 5, []string{"Water", "Waterfall", "Watermelon", "Wave"}},
+		{"fire", 2, []string{"Fire", "Air"}},
+		{"firwork", 5, []string{"Firework"}},
+		{"xyz", 5, nil},
+		{"", 5, nil},
+		{"water", 0, nil},
+	}
+
+	for _, tt := range tests {
+		got := g.Suggest(tt.name, tt.n)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Suggest(%q, %d) = %v, want %v", tt.name, tt.n, got, tt.want)
+		}
+	}
+}
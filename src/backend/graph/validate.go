@@ -0,0 +1,63 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError lists everything Validate found wrong with a graph
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	const shown = 5
+	msg := fmt.Sprintf("invalid recipe data, %d problem(s): %s", len(e.Problems), strings.Join(e.Problems[:min(shown, len(e.Problems))], "; "))
+	if len(e.Problems) > shown {
+		msg += "; ..."
+	}
+	return msg
+}
+
+// Validate checks the invariants the searchers rely on: there are base
+// elements and every recipe is a pair of known ingredients from a lower
+// tier. Elements left without any recipe by the cleaning are allowed. It
+// returns a *ValidationError when any invariant is broken.
+func (g *RecipeGraph) Validate() error {
+	var problems []string
+	if len(g.ElementsInTier(0)) == 0 {
+		problems = append(problems, "no base elements")
+	}
+
+	for _, name := range g.names {
+		el := g.elements[name]
+		if el.Tier < 0 {
+			problems = append(problems, fmt.Sprintf("%q has negative tier %d", name, el.Tier))
+			continue
+		}
+		if el.Special && (el.Tier != 0 || len(el.Recipes) > 0) {
+			problems = append(problems, fmt.Sprintf("special element %q must be tier 0 without recipes", name))
+		}
+		if el.Tier == 0 {
+			continue
+		}
+		for _, recipe := range el.Recipes {
+			if len(recipe) != 2 {
+				problems = append(problems, fmt.Sprintf("%q has a recipe with %d ingredients", name, len(recipe)))
+				continue
+			}
+			for _, ing := range recipe {
+				if !g.Has(ing) {
+					problems = append(problems, fmt.Sprintf("%q uses unknown element %q", name, ing))
+				} else if g.Tier(ing) >= el.Tier {
+					problems = append(problems, fmt.Sprintf("%q (tier %d) uses %q (tier %d)", name, el.Tier, ing, g.Tier(ing)))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
@@ -1,23 +1,128 @@
 package main
-// TO RUN THIS FILE, USE THE COMMAND: go run main.go
+
+// TO RUN THIS PACKAGE, USE THE COMMAND: go run .
+// Other commands: go run . search|scrape|diff|elements -h
 import (
+	"context"
 	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
-	"recipe-finder/search"
-	"recipe-finder/scrape"
 	"os"
+	"recipe-finder/bfs"
+	"recipe-finder/cache"
+	"recipe-finder/graph"
+	"recipe-finder/model"
+	"recipe-finder/scrape"
+	"recipe-finder/search"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 type RecipeRequest struct {
 	Element   string `json:"element"`
-	Algorithm string `json:"algorithm"` // "bfs" or "dfs"
+	Algorithm string `json:"algorithm"` // "bfs", "dfs" or "bidirectional"
 	MaxRecipe int    `json:"maxRecipe"`
+	// Mode "shortest" ranks recipes by their number of combinations and
+	// ignores Algorithm, empty uses Algorithm as is
+	Mode string `json:"mode"`
+	// Owned lists elements the player already has, they are treated as
+	// leaves so only the missing steps are returned
+	Owned []string `json:"owned"`
+	// Format selects the shape of each result, "flat" (the default) or "tree"
+	Format string `json:"format"`
+	// Deterministic trades speed for results that are identical every run
+	Deterministic bool `json:"deterministic"`
+	// Dataset names the recipe data to search in, empty uses the default
+	Dataset string `json:"dataset"`
+	// IncludeSpecial allows recipes that need a special element such as
+	// Time, which have to be unlocked in the game first
+	IncludeSpecial bool `json:"includeSpecial"`
+	// MaxNodes and MaxMemoryMB lower the node and memory budget of BFS and
+	// DFS for this request, 0 keeps the budget of the server
+	MaxNodes    int `json:"maxNodes"`
+	MaxMemoryMB int `json:"maxMemoryMB"`
+	// Resumable keeps a BFS open after the first MaxRecipe recipes so its
+	// results can be paged through /api/recipe/more with the returned
+	// nextCursor
+	Resumable bool `json:"resumable"`
 }
 type RecipeResponse struct {
-	Results     []map[string][]string `json:"results"`
-	Duration    float64                 `json:"duration"`
-	VisitedNode int                     `json:"visitedNode"`
+	// Version is bumped whenever the layout of the response changes
+	Version int    `json:"version"`
+	Format  string `json:"format"`
+	Dataset string `json:"dataset"`
+	// Results holds one map[string][]string per recipe in the flat format
+	// and one *model.TreeNode per recipe in the tree format
+	Results     any     `json:"results"`
+	Duration    float64 `json:"duration"`
+	VisitedNode int     `json:"visitedNode"`
+	Truncated   bool    `json:"truncated"`
+	Steps       []int   `json:"steps,omitempty"`
+	// Special maps every special element used in the results to its
+	// unlock condition
+	Special map[string]string `json:"special,omitempty"`
+	// Budget is "nodes" or "memory" when the search ran out of that
+	// budget, Pruned is the number of states it dropped because of it
+	Budget string `json:"budget,omitempty"`
+	Pruned int    `json:"pruned,omitempty"`
+	// Stats counts the work done by the search, see model.Stats
+	Stats model.Stats `json:"stats"`
+	// Cache is "hit" when the result was served from the cache and "miss"
+	// when it was searched, it is left out when caching is disabled
+	Cache string `json:"cache,omitempty"`
+	// Offset is the position of the first result within a resumable
+	// search and is left out for other searches. NextCursor fetches the
+	// page after this one from /api/recipe/more and is empty once there
+	// are no more recipes.
+	Offset     *int   `json:"offset,omitempty"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Response formats accepted in RecipeRequest.Format
+const (
+	formatFlat = "flat"
+	formatTree = "tree"
+
+	responseVersion = 2
+)
+
+// Values of RecipeResponse.Cache
+const (
+	cacheHit  = "hit"
+	cacheMiss = "miss"
+)
+
+// server holds everything the handlers share
+type server struct {
+	datasets       map[string]*dataset
+	defaultDataset string
+	// swapMu makes swapping the graph and purging the cache one step, so a
+	// search on the old graph cannot add its result in between
+	swapMu sync.Mutex
+	// timeout caps how long a single search may run, 0 means no limit
+	timeout time.Duration
+	// imageDir holds the element icons embedded into SVG exports
+	imageDir string
+	// results caches complete search results, nil disables caching
+	results *cache.Cache
+	// adminToken guards the admin endpoints, empty disables them
+	adminToken string
+	// spillDir and spillAfter are passed to every BFS, see model.Options
+	spillDir   string
+	spillAfter int
+	// maxNodes and maxMemoryMB are the budgets of a single BFS or DFS,
+	// requests may only lower them. 0 means no limit.
+	maxNodes    int
+	maxMemoryMB int
+	// memory is shared by every BFS, DFS and resumable search, nil means
+	// no limit
+	memory *model.MemoryPool
+	// sessions holds the resumable searches being paged, nil disables them
+	sessions *sessionStore
 }
 
 func enableCORS(w http.ResponseWriter) {
@@ -25,23 +130,158 @@ func enableCORS(w http.ResponseWriter) {
 	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 }
-func exploreRecipes(element string, algorithm string, maxRecipe int) ([]map[string][]string, float64, int) {
-	var result []map[string][]string
-	var duration float64
-	var visitedNode int
-	switch algorithm {
+
+// requestOptions adds the search options asked for in req to opts
+func requestOptions(req RecipeRequest, opts model.Options) model.Options {
+	opts.Deterministic = req.Deterministic
+	opts.IncludeSpecial = req.IncludeSpecial
+	if len(req.Owned) > 0 {
+		opts.Owned = make(map[string]bool, len(req.Owned))
+		for _, name := range req.Owned {
+			opts.Owned[name] = true
+		}
+	}
+	return opts
+}
+
+func exploreRecipes(ctx context.Context, g *graph.RecipeGraph, req RecipeRequest, opts model.Options) model.Result {
+	element, maxRecipe := req.Element, req.MaxRecipe
+	opts = requestOptions(req, opts)
+	if req.Mode == "shortest" {
+		return search.Shortest(ctx, g, element, maxRecipe, opts)
+	}
+
+	switch req.Algorithm {
 	case "bfs":
-		result, duration, visitedNode = search.BFS(element, maxRecipe)
+		return search.BFS(ctx, g, element, maxRecipe, opts)
 	case "dfs":
-		result, duration, visitedNode = search.DFS(element, maxRecipe)
+		return search.DFS(ctx, g, element, maxRecipe, opts)
+	case "bidirectional":
+		return search.Bidirectional(ctx, g, element, maxRecipe, opts)
 	default:
-		return nil,0,0
+		return model.Result{}
+	}
+}
+
+// holdsRecipes reports whether the recipes of req are only streamed once
+// the search is done. A deterministic search sorts its result afterwards,
+// so recipes sent as they are found would not be in the stable order.
+// Shortest already finds them in its final order.
+func holdsRecipes(req RecipeRequest) bool {
+	return req.Deterministic && req.Mode != "shortest"
+}
+
+// formatRecipe converts a single recipe into the requested format
+func formatRecipe(g *graph.RecipeGraph, req RecipeRequest, recipe map[string][]string) any {
+	if req.Format == formatTree {
+		return model.BuildTree(g, req.Element, recipe)
+	}
+	return recipe
+}
+
+// formatResults converts every recipe of a result into the requested format
+func formatResults(g *graph.RecipeGraph, req RecipeRequest, recipes []map[string][]string) any {
+	if req.Format != formatTree {
+		return recipes
+	}
+	trees := make([]*model.TreeNode, len(recipes))
+	for i, recipe := range recipes {
+		trees[i] = model.BuildTree(g, req.Element, recipe)
+	}
+	return trees
+}
+
+// specialElements collects the special elements used in recipes together
+// with their unlock condition, nil when there are none
+func specialElements(g *graph.RecipeGraph, recipes []map[string][]string) map[string]string {
+	var special map[string]string
+	for _, recipe := range recipes {
+		for _, ingredients := range recipe {
+			for _, name := range ingredients {
+				if !g.IsSpecial(name) {
+					continue
+				}
+				if special == nil {
+					special = make(map[string]string)
+				}
+				special[name] = g.Unlock(name)
+			}
+		}
+	}
+	return special
+}
+
+// cacheKey identifies every field of req that changes the search result,
+// Format is left out since it is applied afterwards
+func cacheKey(req RecipeRequest) string {
+	algorithm := req.Algorithm
+	if req.Mode == "shortest" {
+		algorithm = ""
+	}
+	owned := append([]string(nil), req.Owned...)
+	sort.Strings(owned)
+	return fmt.Sprintf("%s|%s|%s|%s|%d|%t|%t|%s", req.Dataset, req.Element, algorithm, req.Mode, req.MaxRecipe, req.Deterministic, req.IncludeSpecial, strings.Join(owned, ","))
+}
+
+// budgetLimit combines the budget of the server with the one requested,
+// the smaller one wins and 0 means no limit
+func budgetLimit(server, requested int) int {
+	if server == 0 || (requested > 0 && requested < server) {
+		return requested
+	}
+	return server
+}
+
+// searchOptions adds the server's search settings for req to opts
+func (s *server) searchOptions(req RecipeRequest, opts model.Options) model.Options {
+	opts.SpillDir, opts.SpillAfter = s.spillDir, s.spillAfter
+	opts.MaxNodes = budgetLimit(s.maxNodes, req.MaxNodes)
+	opts.MaxMemory = int64(budgetLimit(s.maxMemoryMB, req.MaxMemoryMB)) << 20
+	opts.MemoryPool = s.memory
+	return opts
+}
+
+// explore runs exploreRecipes through the result cache. On a hit the cached
+// recipes are still passed to opts.OnRecipe. Results of cancelled searches
+// and of searches that ran out of budget are incomplete and never cached,
+// so the budget is left out of the cache key.
+func (s *server) explore(ctx context.Context, g *graph.RecipeGraph, req RecipeRequest, opts model.Options) (model.Result, string) {
+	opts = s.searchOptions(req, opts)
+	if s.results == nil {
+		return exploreRecipes(ctx, g, req, opts), ""
+	}
+
+	key := cacheKey(req)
+	if result, ok := s.results.Get(key); ok {
+		if opts.OnRecipe != nil {
+			for _, recipe := range result.Recipes {
+				opts.OnRecipe(recipe)
+			}
+		}
+		return result, cacheHit
 	}
-	return result, duration, visitedNode
+
+	result := exploreRecipes(ctx, g, req, opts)
+	if !result.Truncated && result.Budget == "" {
+		s.swapMu.Lock()
+		// Hasil dari graph lama tidak disimpan kalau data sudah di-reload
+		if s.datasets[req.Dataset].graph.Load() == g {
+			s.results.Add(key, result)
+		}
+		s.swapMu.Unlock()
+	}
+	return result, cacheMiss
 }
 
+// searchContext derives the context for a single search from the request
+func (s *server) searchContext(r *http.Request) (context.Context, context.CancelFunc) {
+	if s.timeout > 0 {
+		return context.WithTimeout(r.Context(), s.timeout)
+	}
+	return context.WithCancel(r.Context())
+}
 
-func handleRecipe(w http.ResponseWriter, r *http.Request) {
+func (s *server) handleRecipe(w http.ResponseWriter, r *http.Request) {
 	enableCORS(w)
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusOK)
@@ -49,29 +289,151 @@ func handleRecipe(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if r.Method != http.MethodPost {
-		http.Error(w, "Only POST allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, http.MethodPost)
 		return
 	}
 
 	var req RecipeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, newAPIError(http.StatusBadRequest, codeInvalidRequest, "invalid request body: %v", err))
+		return
+	}
+	g, apiErr := s.graphFor(&req.Dataset)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	if err := validateRequest(g, &req); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	result, duration, visitedNode := exploreRecipes(req.Element, req.Algorithm, req.MaxRecipe)
-	response := RecipeResponse{
-		Results:     result,
-		Duration:    duration,
-		VisitedNode: visitedNode,
+	ctx, cancel := s.searchContext(r)
+	defer cancel()
+
+	var result model.Result
+	var cacheStatus, cursor string
+	if req.Resumable && s.sessions == nil {
+		writeError(w, newAPIError(http.StatusBadRequest, codeInvalidRequest, "resumable searches are disabled on this server"))
+		return
+	}
+	if req.Resumable {
+		// Sesi harus menyimpan frontier-nya sendiri, jadi tidak lewat cache
+		result, cursor = s.startSession(ctx, g, req)
+	} else {
+		result, cacheStatus = s.explore(ctx, g, req, model.Options{})
+	}
+	if err := timeoutError(ctx, req, result); err != nil {
+		// Client tidak menerima cursor-nya, jadi sesinya langsung dibuang
+		if id, _, ok := decodeCursor(cursor); ok {
+			s.sessions.delete(id)
+		}
+		writeError(w, err)
+		return
+	}
+	response := recipeResponse(g, req, result)
+	response.Cache, response.NextCursor = cacheStatus, cursor
+	if req.Resumable {
+		offset := 0
+		response.Offset = &offset
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-func main() {
-	http.HandleFunc("/api/recipe", handleRecipe)
-	scrape.ScrapeToJsonComplete()
+// recipeResponse builds the response of /api/recipe for result
+func recipeResponse(g *graph.RecipeGraph, req RecipeRequest, result model.Result) RecipeResponse {
+	return RecipeResponse{
+		Version:     responseVersion,
+		Format:      req.Format,
+		Dataset:     req.Dataset,
+		Results:     formatResults(g, req, result.Recipes),
+		Duration:    result.Duration,
+		VisitedNode: result.VisitedNode,
+		Truncated:   result.Truncated,
+		Steps:       result.Steps,
+		Special:     specialElements(g, result.Recipes),
+		Budget:      result.Budget,
+		Pruned:      result.Pruned,
+		Stats:       result.Stats,
+	}
+}
+
+// runServe starts the HTTP server, it only returns when the server fails
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	htmlPath := fs.String("html", "", "parse a saved HTML snapshot of the wiki instead of fetching it")
+	forceScrape := fs.Bool("scrape", false, "scrape the recipes again even if the data file already exists")
+	timeout := fs.Duration("timeout", 30*time.Second, "maximum duration of a single search, 0 disables the limit")
+	imageDir := fs.String("images", "../frontend/recipe-finder/public/images", "directory of element icons used in SVG exports")
+	cacheSize := fs.Int("cache-size", 256, "number of search results kept in memory, 0 disables the cache")
+	cacheTTL := fs.Duration("cache-ttl", 10*time.Minute, "how long a cached result stays valid, 0 keeps it until evicted")
+	watch := fs.Duration("watch", 0, "check the recipe data for changes this often and reload it, 0 disables watching")
+	adminToken := fs.String("admin-token", os.Getenv("ADMIN_TOKEN"), "bearer token for /api/admin/reload, empty disables the endpoint")
+	spillDir := fs.String("spill-dir", "", "let BFS spill its frontier to temporary files in this directory instead of keeping it in memory")
+	spillAfter := fs.Int("spill-after", bfs.DefaultSpillAfter, "number of BFS frontier states kept in memory before spilling")
+	maxNodes := fs.Int("max-nodes", 0, "maximum number of nodes a single BFS or DFS may visit, 0 disables the limit")
+	maxMemory := fs.Int("max-memory-mb", 512, "maximum estimated megabytes of states a single BFS or DFS may hold, 0 disables the limit")
+	maxTotalMemory := fs.Int("max-total-memory-mb", 2048, "maximum estimated megabytes of states held by all BFS, DFS and resumable searches together, 0 disables the limit")
+	sessions := fs.Int("sessions", 64, "number of resumable searches kept for paging, 0 disables resumable searches")
+	sessionTTL := fs.Duration("session-ttl", 10*time.Minute, "how long a resumable search is kept after it was last used, 0 keeps it until evicted")
+	var datasets datasetFlags
+	fs.Var(&datasets, "dataset", "serve the recipe JSON at path as dataset name (name=path), can be repeated, the first one is the default")
+	fs.Parse(args)
+
+	if len(datasets) == 0 {
+		datasets.Set(scrape.LittleAlchemy2.Name + "=" + scrape.LittleAlchemy2.DataPath)
+	}
+
+	s := &server{
+		datasets:       make(map[string]*dataset),
+		defaultDataset: datasets[0].name,
+		timeout:        *timeout,
+		imageDir:       *imageDir,
+		results:        cache.New(*cacheSize, *cacheTTL),
+		adminToken:     *adminToken,
+		spillDir:       *spillDir,
+		spillAfter:     *spillAfter,
+		maxNodes:       *maxNodes,
+		maxMemoryMB:    *maxMemory,
+		memory:         model.NewMemoryPool(int64(*maxTotalMemory) << 20),
+		sessions:       newSessionStore(*sessions, *sessionTTL),
+	}
+	go s.sessions.expireLoop()
+	for i, spec := range datasets {
+		// Dataset bawaan di-scrape jika file-nya belum ada, -html dan -scrape hanya untuk dataset default
+		if src, ok := scrape.Sources[spec.name]; ok {
+			html, force := "", false
+			if i == 0 {
+				html, force = *htmlPath, *forceScrape
+			}
+			if err := prepareData(src, spec.path, html, force); err != nil {
+				return err
+			}
+		}
+
+		ds := &dataset{name: spec.name, path: spec.path}
+		if _, err := s.reload(ds); err != nil {
+			return fmt.Errorf("loading recipes of %s: %w", spec.name, err)
+		}
+		s.datasets[spec.name] = ds
+		if *watch > 0 {
+			go s.watchData(ds, *watch)
+		}
+	}
+	http.HandleFunc("/api/recipe", s.handleRecipe)
+	http.HandleFunc("/api/recipe/more", s.handleRecipeMore)
+	http.HandleFunc("/api/recipe/stream", s.handleRecipeStream)
+	http.HandleFunc("/api/recipe/progress", s.handleRecipeProgress)
+	http.HandleFunc("/api/recipe/export", s.handleRecipeExport)
+	http.HandleFunc("/api/elements", s.handleElements)
+	http.HandleFunc("/api/elements/{name}", s.handleElement)
+	http.HandleFunc("/api/elements/{name}/uses", s.handleElementUses)
+	http.HandleFunc("/api/reachable", s.handleReachable)
+	http.HandleFunc("/api/datasets", s.handleDatasets)
+	if s.adminToken != "" {
+		http.HandleFunc("/api/admin/reload", s.handleReload)
+	}
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -79,6 +441,27 @@ func main() {
 	}
 
 	log.Printf("Server running on http://localhost:%s", port)
-	log.Fatal(http.ListenAndServe(":" + port, nil))
+	return http.ListenAndServe(":"+port, nil)
+}
 
+func main() {
+	// Tanpa subcommand (atau diawali flag) tetap menjalankan server seperti sebelumnya
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		fmt.Print(usage)
+		return
+	}
+
+	command, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, usage)
+		os.Exit(2)
+	}
+	if err := command(args); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
 }
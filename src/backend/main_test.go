@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"recipe-finder/graph/graphtest"
+	"recipe-finder/model"
+	"strings"
+	"testing"
+)
+
+// newTestServer serves the recipe JSON of every name=path spec, the first
+// one being the default dataset
+func newTestServer(t *testing.T, specs ...string) *server {
+	t.Helper()
+	s := &server{datasets: make(map[string]*dataset)}
+	for _, spec := range specs {
+		name, path, _ := strings.Cut(spec, "=")
+		if s.defaultDataset == "" {
+			s.defaultDataset = name
+		}
+		ds := &dataset{name: name, path: path}
+		if _, err := s.reload(ds); err != nil {
+			t.Fatalf("loading %s: %v", spec, err)
+		}
+		s.datasets[name] = ds
+	}
+	return s
+}
+
+// newSmallServer serves graphtest.Small as the only dataset
+func newSmallServer() *server {
+	ds := &dataset{name: "small"}
+	ds.graph.Store(graphtest.Small())
+	return &server{datasets: map[string]*dataset{"small": ds}, defaultDataset: "small"}
+}
+
+// postRecipe sends req to /api/recipe and decodes the response into v
+func postRecipe(t *testing.T, s *server, req RecipeRequest, v any) int {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	s.handleRecipe(rec, httptest.NewRequest(http.MethodPost, "/api/recipe", bytes.NewReader(body)))
+	if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+		t.Fatalf("decoding response of %s: %v", req.Element, err)
+	}
+	return rec.Code
+}
+
+// treeResponse is a RecipeResponse in the tree format
+type treeResponse struct {
+	RecipeResponse
+	Results []*model.TreeNode `json:"results"`
+}
+
+func hasSpecialNode(node *model.TreeNode, name string) bool {
+	if node.Name == name && node.Special {
+		return true
+	}
+	for _, child := range node.Children {
+		if hasSpecialNode(child, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHandleRecipeSpecial(t *testing.T) {
+	// testdata/la2.json di-scrape dari scrape/testdata/la2.html, Cloud bisa dibuat dari Steam dan Time
+	s := newTestServer(t, "la2=testdata/la2.json")
+
+	tests := []struct {
+		algorithm string
+		mode      string
+		special   bool
+		want      int
+	}{
+		{"bfs", "", false, 2},
+		{"bfs", "", true, 4},
+		{"dfs", "", true, 4},
+		{"bidirectional", "", true, 4},
+		{"", "shortest", true, 4},
+	}
+
+	for _, tt := range tests {
+		req := RecipeRequest{Element: "Cloud", Algorithm: tt.algorithm, Mode: tt.mode, MaxRecipe: 10, Format: formatTree, Deterministic: true, IncludeSpecial: tt.special}
+		var resp treeResponse
+		if code := postRecipe(t, s, req, &resp); code != http.StatusOK {
+			t.Fatalf("%s %s: status %d", tt.algorithm, tt.mode, code)
+		}
+		if len(resp.Results) != tt.want {
+			t.Errorf("%s %s special %v: %d recipes, want %d", tt.algorithm, tt.mode, tt.special, len(resp.Results), tt.want)
+			continue
+		}
+
+		marked := 0
+		for _, tree := range resp.Results {
+			if hasSpecialNode(tree, "Time") {
+				marked++
+			}
+		}
+		if !tt.special {
+			if marked != 0 || resp.Special != nil {
+				t.Errorf("%s %s: recipes use Time without includeSpecial", tt.algorithm, tt.mode)
+			}
+			continue
+		}
+		// Dua dari empat resep memakai Time, node-nya harus ditandai spesial
+		if marked != 2 {
+			t.Errorf("%s %s: %d recipes mark Time as special, want 2", tt.algorithm, tt.mode, marked)
+		}
+		if resp.Special["Time"] != "Discover 100 elements." {
+			t.Errorf("%s %s: special = %v, want the unlock condition of Time", tt.algorithm, tt.mode, resp.Special)
+		}
+	}
+}
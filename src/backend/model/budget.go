@@ -0,0 +1,124 @@
+package model
+
+import "sync/atomic"
+
+// Budgets reported in Result.Budget
+const (
+	BudgetNodes  = "nodes"
+	BudgetMemory = "memory"
+)
+
+// MemoryPool caps the memory held by several searches together, on top of
+// the budget of each one. It is safe for concurrent use.
+type MemoryPool struct {
+	max  int64
+	used atomic.Int64
+}
+
+// NewMemoryPool creates a pool of maxBytes, nil when maxBytes is 0 or less.
+// A nil pool has no limit.
+func NewMemoryPool(maxBytes int64) *MemoryPool {
+	if maxBytes <= 0 {
+		return nil
+	}
+	return &MemoryPool{max: maxBytes}
+}
+
+// Used returns the bytes held by every search using the pool
+func (p *MemoryPool) Used() int64 {
+	if p == nil {
+		return 0
+	}
+	return p.used.Load()
+}
+
+func (p *MemoryPool) reserve(bytes int64) bool {
+	if p == nil {
+		return true
+	}
+	if p.used.Add(bytes) > p.max {
+		p.used.Add(-bytes)
+		return false
+	}
+	return true
+}
+
+func (p *MemoryPool) track(bytes int64) {
+	if p != nil {
+		p.used.Add(bytes)
+	}
+}
+
+// Budget enforces Options.MaxNodes, Options.MaxMemory and Options.MemoryPool
+// for one search. It is shared by every worker of the search and safe for
+// concurrent use.
+type Budget struct {
+	maxNodes  int64
+	maxMemory int64
+	pool      *MemoryPool
+	nodes     atomic.Int64
+	memory    atomic.Int64
+	pruned    atomic.Int64
+	hit       atomic.Value
+}
+
+// NewBudget creates the budget of a single search from opts
+func NewBudget(opts Options) *Budget {
+	return &Budget{maxNodes: int64(opts.MaxNodes), maxMemory: opts.MaxMemory, pool: opts.MemoryPool}
+}
+
+// Visit counts n visited nodes and reports whether the search may go on
+func (b *Budget) Visit(n int) bool {
+	if b.nodes.Add(int64(n)) > b.maxNodes && b.maxNodes > 0 {
+		b.hit.CompareAndSwap(nil, BudgetNodes)
+		return false
+	}
+	return true
+}
+
+// OutOfNodes reports whether the search visited more nodes than allowed
+func (b *Budget) OutOfNodes() bool {
+	return b.maxNodes > 0 && b.nodes.Load() > b.maxNodes
+}
+
+// Reserve claims bytes for a new state. It fails once the states held by
+// the search, or by every search sharing its memory pool, would exceed
+// the memory budget. The caller then prunes the state instead of keeping
+// it.
+func (b *Budget) Reserve(bytes int64) bool {
+	if b.memory.Add(bytes) > b.maxMemory && b.maxMemory > 0 || !b.pool.reserve(bytes) {
+		b.memory.Add(-bytes)
+		b.hit.CompareAndSwap(nil, BudgetMemory)
+		return false
+	}
+	return true
+}
+
+// Track adjusts the memory held without checking the budget, e.g. when a
+// state is dropped or read back from disk
+func (b *Budget) Track(bytes int64) {
+	b.memory.Add(bytes)
+	b.pool.track(bytes)
+}
+
+// Close gives the memory still held by the search back to its pool. It is
+// called once the search is done, the budget must not be used afterwards.
+func (b *Budget) Close() {
+	b.pool.track(-b.memory.Swap(0))
+}
+
+// Prune counts n states that were dropped because of the budget
+func (b *Budget) Prune(n int) {
+	b.pruned.Add(int64(n))
+}
+
+// Hit returns the budget that was exceeded first, empty if none was
+func (b *Budget) Hit() string {
+	hit, _ := b.hit.Load().(string)
+	return hit
+}
+
+// Pruned returns the number of states dropped because of the budget
+func (b *Budget) Pruned() int {
+	return int(b.pruned.Load())
+}
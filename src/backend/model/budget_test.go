@@ -0,0 +1,68 @@
+package model
+
+import "testing"
+
+func TestBudgetMemory(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxMemory int64
+		pool      int64
+		reserve   []int64
+		want      []bool
+	}{
+		{"no limit", 0, 0, []int64{100, 1 << 40}, []bool{true, true}},
+		{"own budget", 100, 0, []int64{60, 50, 40}, []bool{true, false, true}},
+		{"pool", 0, 100, []int64{60, 50, 40}, []bool{true, false, true}},
+		// Yang lebih kecil di antara budget sendiri dan pool yang berlaku
+		{"pool smaller", 200, 100, []int64{90, 20}, []bool{true, false}},
+		{"budget smaller", 100, 200, []int64{90, 20}, []bool{true, false}},
+	}
+
+	for _, tt := range tests {
+		pool := NewMemoryPool(tt.pool)
+		b := NewBudget(Options{MaxMemory: tt.maxMemory, MemoryPool: pool})
+		for i, bytes := range tt.reserve {
+			if got := b.Reserve(bytes); got != tt.want[i] {
+				t.Errorf("%s: Reserve(%d) = %v, want %v", tt.name, bytes, got, tt.want[i])
+			}
+		}
+		pruned := false
+		for _, ok := range tt.want {
+			pruned = pruned || !ok
+		}
+		if hit := b.Hit(); pruned != (hit == BudgetMemory) {
+			t.Errorf("%s: Hit = %q", tt.name, hit)
+		}
+	}
+}
+
+func TestMemoryPoolShared(t *testing.T) {
+	pool := NewMemoryPool(100)
+	a := NewBudget(Options{MemoryPool: pool})
+	b := NewBudget(Options{MemoryPool: pool})
+
+	if !a.Reserve(70) || b.Reserve(40) || !b.Reserve(30) {
+		t.Fatalf("budgets sharing a pool of 100 did not split it")
+	}
+	a.Track(-20)
+	if used := pool.Used(); used != 80 {
+		t.Errorf("Used = %d after releasing 20, want 80", used)
+	}
+
+	// Close mengembalikan semua memori yang masih dipegang pencarian
+	a.Close()
+	if used := pool.Used(); used != 30 {
+		t.Errorf("Used = %d after closing a, want 30", used)
+	}
+	if !b.Reserve(70) || b.Reserve(1) {
+		t.Errorf("memory given back by a cannot be reserved exactly by b")
+	}
+	b.Close()
+	if used := pool.Used(); used != 0 {
+		t.Errorf("Used = %d after closing every budget, want 0", used)
+	}
+
+	if NewMemoryPool(0) != nil || NewMemoryPool(-1) != nil {
+		t.Errorf("NewMemoryPool without a limit is not nil")
+	}
+}
@@ -0,0 +1,81 @@
+package model
+
+import "sync/atomic"
+
+// Stats describes the work done by one search. Every searcher counts the
+// same things, so the numbers can be compared between algorithms.
+type Stats struct {
+	// Expanded is the number of states taken from the frontier and
+	// expanded by one element
+	Expanded int `json:"expanded"`
+	// Generated is the number of states added to the frontier
+	Generated int `json:"generated"`
+	// RecipesConsidered is the number of recipes looked at while
+	// expanding, including those rejected for their tier
+	RecipesConsidered int `json:"recipesConsidered"`
+	// Duplicates is the number of complete recipes discarded because the
+	// same recipe was already found
+	Duplicates int `json:"duplicates"`
+	// PeakFrontier is the largest number of states waiting at once
+	PeakFrontier int `json:"peakFrontier"`
+}
+
+// VisitedNodes is the value reported as Result.VisitedNode: the target
+// plus both ingredients of every recipe considered
+func (s Stats) VisitedNodes() int {
+	return 1 + 2*s.RecipesConsidered
+}
+
+// Metrics collects Stats while a search runs. The counters are updated
+// atomically so every worker of a search can share one Metrics.
+type Metrics struct {
+	expanded   atomic.Int64
+	generated  atomic.Int64
+	recipes    atomic.Int64
+	duplicates atomic.Int64
+	peak       atomic.Int64
+}
+
+// Expand counts one expanded state
+func (m *Metrics) Expand() {
+	m.expanded.Add(1)
+}
+
+// Generate counts one state added to a frontier that now holds frontier
+// states
+func (m *Metrics) Generate(frontier int) {
+	m.generated.Add(1)
+	m.Frontier(frontier)
+}
+
+// Consider counts one recipe looked at while expanding and returns the
+// number of recipes considered so far
+func (m *Metrics) Consider() int {
+	return int(m.recipes.Add(1))
+}
+
+// Duplicate counts one complete recipe discarded as already found
+func (m *Metrics) Duplicate() {
+	m.duplicates.Add(1)
+}
+
+// Frontier records the current size of the frontier for PeakFrontier
+func (m *Metrics) Frontier(size int) {
+	for {
+		peak := m.peak.Load()
+		if int64(size) <= peak || m.peak.CompareAndSwap(peak, int64(size)) {
+			return
+		}
+	}
+}
+
+// Stats returns the counters collected so far
+func (m *Metrics) Stats() Stats {
+	return Stats{
+		Expanded:          int(m.expanded.Load()),
+		Generated:         int(m.generated.Load()),
+		RecipesConsidered: int(m.recipes.Load()),
+		Duplicates:        int(m.duplicates.Load()),
+		PeakFrontier:      int(m.peak.Load()),
+	}
+}
@@ -0,0 +1,171 @@
+package model
+
+import (
+	"recipe-finder/graph"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Result is what every searcher returns
+type Result struct {
+	Recipes  []map[string][]string
+	Duration float64
+	// VisitedNode is Stats.VisitedNodes, kept for older clients
+	VisitedNode int
+	// Truncated is set when the search was cancelled, hit its deadline or
+	// ran out of budget before finding maxRecipe recipes or exhausting the
+	// search space
+	Truncated bool
+	// Steps holds the number of combinations of each recipe, only filled
+	// by searchers that rank their results
+	Steps []int
+	// Budget is BudgetNodes or BudgetMemory when the search ran out of
+	// that budget, Pruned is the number of states it dropped because of it
+	Budget string
+	Pruned int
+	// Stats counts the work done by the search
+	Stats Stats
+}
+
+// Options tweaks how a search runs, the zero value keeps the defaults
+type Options struct {
+	// OnRecipe is called once for every new unique recipe in the order they
+	// are added to the result. It runs on the search's own goroutine, so
+	// blocking in it slows the search down instead of dropping recipes.
+	OnRecipe func(map[string][]string)
+	// OnProgress receives every expansion step of the search. It is called
+	// from all workers at once and must be safe for concurrent use.
+	OnProgress func(Event)
+	// Owned elements are treated like base elements: they are never
+	// expanded and need no recipe of their own
+	Owned map[string]bool
+	// IncludeSpecial lets the search use recipes that need a special
+	// element such as Time, which are skipped by default
+	IncludeSpecial bool
+	// SpillDir lets BFS write the part of its frontier beyond SpillAfter
+	// states to temporary files in this directory, empty keeps the whole
+	// frontier in memory
+	SpillDir string
+	// SpillAfter is the number of frontier states BFS keeps in memory
+	// before it spills, 0 uses bfs.DefaultSpillAfter
+	SpillAfter int
+	// MaxNodes caps the number of nodes BFS and DFS may visit, the search
+	// stops once it is reached. 0 means no limit.
+	MaxNodes int
+	// MaxMemory caps the estimated bytes of the states BFS and DFS keep at
+	// once, new states are pruned while it is reached. 0 means no limit.
+	MaxMemory int64
+	// MemoryPool is shared by searches whose states together may not
+	// exceed it, such as every search of a server. nil means no limit.
+	MemoryPool *MemoryPool
+	// Deterministic runs the search with a single worker so the same
+	// request always finds the same recipes, regardless of the number of
+	// CPUs, and sorts them with SortRecipes
+	Deterministic bool
+}
+
+// IsLeaf reports whether a search should stop expanding at name
+func (o Options) IsLeaf(g *graph.RecipeGraph, name string) bool {
+	return g.Tier(name) == 0 || o.Owned[name]
+}
+
+// Recipes returns the recipes of name the search may use
+func (o Options) Recipes(g *graph.RecipeGraph, name string) [][]string {
+	if o.IncludeSpecial {
+		return g.Recipes(name)
+	}
+	return g.StandardRecipes(name)
+}
+
+// Event types sent to Options.OnProgress
+const (
+	EventExpand  = "expand"
+	EventEnqueue = "enqueue"
+	EventFound   = "found"
+)
+
+// Event describes one step of a running search, used to visualize it
+type Event struct {
+	Type string `json:"type"`
+	// Element is the element being expanded, or whose recipe was chosen
+	// for the enqueued state
+	Element string   `json:"element,omitempty"`
+	Recipe  []string `json:"recipe,omitempty"`
+	// Frontier is the number of states waiting in the queue or stack
+	Frontier int `json:"frontier"`
+	// Result is the complete recipe map of a found event
+	Result map[string][]string `json:"result,omitempty"`
+}
+
+// SampleProgress passes only every nth expand and enqueue event on to fn,
+// found events always go through
+func SampleProgress(n int, fn func(Event)) func(Event) {
+	if n <= 1 {
+		return fn
+	}
+	var count atomic.Int64
+	return func(event Event) {
+		if event.Type != EventFound && count.Add(1)%int64(n) != 0 {
+			return
+		}
+		fn(event)
+	}
+}
+
+// Fingerprint creates a canonical string of a recipe map for deduplication
+func Fingerprint(recipe map[string][]string) string {
+	elements := make([]string, 0, len(recipe))
+	for element := range recipe {
+		elements = append(elements, element)
+	}
+	sort.Strings(elements)
+
+	var fingerprint strings.Builder
+	for _, element := range elements {
+		// Diurutkan pada salinan karena slice dipakai bersama dengan graph
+		components := append([]string(nil), recipe[element]...)
+		sort.Strings(components)
+
+		fingerprint.WriteString(element)
+		fingerprint.WriteString(":[")
+		fingerprint.WriteString(strings.Join(components, ","))
+		fingerprint.WriteString("]")
+	}
+	return fingerprint.String()
+}
+
+// SortRecipes orders recipes by their number of elements, ties are broken by
+// their Fingerprint so the order never depends on how they were found
+func SortRecipes(recipes []map[string][]string) {
+	type keyed struct {
+		recipe      map[string][]string
+		fingerprint string
+	}
+	items := make([]keyed, len(recipes))
+	for i, recipe := range recipes {
+		items[i] = keyed{recipe, Fingerprint(recipe)}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if len(items[i].recipe) != len(items[j].recipe) {
+			return len(items[i].recipe) < len(items[j].recipe)
+		}
+		return items[i].fingerprint < items[j].fingerprint
+	})
+	for i, item := range items {
+		recipes[i] = item.recipe
+	}
+}
+
+// SameRecipe reports whether two ingredient lists are identical
+func SameRecipe(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		recipe map[string][]string
+		want   string
+	}{
+		{map[string][]string{}, ""},
+		{map[string][]string{"Water": {}}, "Water:[]"},
+		{map[string][]string{"Steam": {"Water", "Air"}, "Cloud": {"Steam", "Air"}}, "Cloud:[Air,Steam]Steam:[Air,Water]"},
+		// Urutan bahan tidak mengubah fingerprint
+		{map[string][]string{"Cloud": {"Air", "Steam"}, "Steam": {"Air", "Water"}}, "Cloud:[Air,Steam]Steam:[Air,Water]"},
+	}
+
+	for _, tt := range tests {
+		if got := Fingerprint(tt.recipe); got != tt.want {
+			t.Errorf("Fingerprint(%v) = %q, want %q", tt.recipe, got, tt.want)
+		}
+	}
+
+	// Slice bahan dipakai bersama dengan graph, jadi tidak boleh ikut terurut
+	recipe := map[string][]string{"Steam": {"Water", "Air"}}
+	Fingerprint(recipe)
+	if !reflect.DeepEqual(recipe["Steam"], []string{"Water", "Air"}) {
+		t.Errorf("Fingerprint sorted the ingredients in place: %v", recipe["Steam"])
+	}
+}
+
+func TestSortRecipes(t *testing.T) {
+	long := map[string][]string{"Rain": {"Steam", "Stone"}, "Steam": {"Air", "Water"}, "Stone": {"Air", "Lava"}, "Lava": {"Earth", "Fire"}}
+	shortB := map[string][]string{"Rain": {"Cloud", "Water"}, "Cloud": {"Air", "Steam"}, "Steam": {"Fire", "Water"}}
+	shortA := map[string][]string{"Rain": {"Cloud", "Water"}, "Cloud": {"Air", "Steam"}, "Steam": {"Air", "Water"}}
+	want := []map[string][]string{shortA, shortB, long}
+
+	// Urutan hasil selalu sama, apa pun urutan resep ditemukan
+	for _, recipes := range [][]map[string][]string{
+		{long, shortB, shortA},
+		{shortB, long, shortA},
+		{shortA, shortB, long},
+	} {
+		SortRecipes(recipes)
+		if !reflect.DeepEqual(recipes, want) {
+			t.Errorf("SortRecipes = %v, want %v", recipes, want)
+		}
+	}
+	SortRecipes(nil)
+}
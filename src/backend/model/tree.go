@@ -0,0 +1,38 @@
+package model
+
+import "recipe-finder/graph"
+
+// TreeNode is one element of a recipe written out as a nested tree. An
+// element used twice in a recipe appears as two nodes with their own ids.
+type TreeNode struct {
+	// ID is unique within one tree, assigned in pre-order from 0
+	ID       int         `json:"id"`
+	Name     string      `json:"name"`
+	Tier     int         `json:"tier"`
+	Depth    int         `json:"depth"`
+	Children []*TreeNode `json:"children,omitempty"`
+	// Special marks elements that are unlocked instead of combined
+	Special bool   `json:"special,omitempty"`
+	Unlock  string `json:"unlock,omitempty"`
+}
+
+// BuildTree expands a flat recipe map into a tree rooted at element.
+// Elements without an entry in the map, such as base or owned elements,
+// become leaves.
+func BuildTree(g *graph.RecipeGraph, element string, recipe map[string][]string) *TreeNode {
+	nextID := 0
+	var build func(name string, depth int) *TreeNode
+	build = func(name string, depth int) *TreeNode {
+		node := &TreeNode{ID: nextID, Name: name, Tier: g.Tier(name), Depth: depth, Special: g.IsSpecial(name), Unlock: g.Unlock(name)}
+		nextID++
+		for _, ingredient := range recipe[name] {
+			// Bahan selalu bertier lebih rendah, cek ini menjaga dari peta yang rusak
+			if g.Tier(ingredient) >= node.Tier {
+				continue
+			}
+			node.Children = append(node.Children, build(ingredient, depth+1))
+		}
+		return node
+	}
+	return build(element, 0)
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"recipe-finder/graph"
+	"strings"
+	"testing"
+)
+
+// shape writes a tree as name(children) with the id and depth of every
+// node, special nodes get a star
+func shape(node *TreeNode) string {
+	var sb strings.Builder
+	var write func(node *TreeNode, depth int)
+	write = func(node *TreeNode, depth int) {
+		sb.WriteString(node.Name)
+		if node.Special {
+			sb.WriteString("*")
+		}
+		if node.Depth != depth {
+			sb.WriteString("!depth")
+		}
+		if len(node.Children) == 0 {
+			return
+		}
+		sb.WriteString("(")
+		for i, child := range node.Children {
+			if i > 0 {
+				sb.WriteString(" ")
+			}
+			write(child, depth+1)
+		}
+		sb.WriteString(")")
+	}
+	write(node, 0)
+	return sb.String()
+}
+
+func TestBuildTree(t *testing.T) {
+	g := graph.New(map[string]graph.Recipe{
+		"Air":   {Tier: 0},
+		"Earth": {Tier: 0},
+		"Fire":  {Tier: 0},
+		"Water": {Tier: 0},
+		"Time":  {Tier: 0, Special: true, Unlock: "Discover 100 elements."},
+		"Lava":  {Tier: 1, Recipes: [][]string{{"Earth", "Fire"}}},
+		"Mud":   {Tier: 1, Recipes: [][]string{{"Earth", "Water"}}},
+		"Stone": {Tier: 2, Recipes: [][]string{{"Lava", "Mud"}}},
+		"Sand":  {Tier: 3, Recipes: [][]string{{"Stone", "Time"}}},
+		"Glass": {Tier: 4, Recipes: [][]string{{"Fire", "Sand"}}},
+	})
+
+	tests := []struct {
+		name    string
+		element string
+		recipe  map[string][]string
+		want    string
+		nodes   int
+	}{
+		{"base", "Water", map[string][]string{"Water": {}}, "Water", 1},
+		{"chain", "Stone", map[string][]string{
+			"Stone": {"Lava", "Mud"}, "Lava": {"Earth", "Fire"}, "Mud": {"Earth", "Water"},
+		}, "Stone(Lava(Earth Fire) Mud(Earth Water))", 7},
+		{"special", "Glass", map[string][]string{
+			"Glass": {"Fire", "Sand"}, "Sand": {"Stone", "Time"}, "Stone": {"Lava", "Mud"}, "Lava": {"Earth", "Fire"}, "Mud": {"Earth", "Water"},
+		}, "Glass(Fire Sand(Stone(Lava(Earth Fire) Mud(Earth Water)) Time*))", 11},
+		// Elemen tanpa entri, misalnya yang dimiliki, menjadi leaf
+		{"owned", "Glass", map[string][]string{"Glass": {"Fire", "Sand"}}, "Glass(Fire Sand)", 3},
+		// Bahan dengan tier yang tidak lebih rendah dilewati agar peta rusak tidak berulang
+		{"broken", "Lava", map[string][]string{"Lava": {"Stone", "Fire"}, "Stone": {"Lava", "Mud"}}, "Lava(Fire)", 2},
+	}
+
+	for _, tt := range tests {
+		tree := BuildTree(g, tt.element, tt.recipe)
+		if got := shape(tree); got != tt.want {
+			t.Errorf("%s: tree %s, want %s", tt.name, got, tt.want)
+		}
+
+		// Id diberikan pre-order mulai dari 0
+		next := 0
+		var check func(node *TreeNode)
+		check = func(node *TreeNode) {
+			if node.ID != next {
+				t.Errorf("%s: %s has id %d, want %d", tt.name, node.Name, node.ID, next)
+			}
+			if node.Tier != g.Tier(node.Name) {
+				t.Errorf("%s: %s has tier %d", tt.name, node.Name, node.Tier)
+			}
+			next++
+			for _, child := range node.Children {
+				check(child)
+			}
+		}
+		check(tree)
+		if next != tt.nodes {
+			t.Errorf("%s: %d nodes, want %d", tt.name, next, tt.nodes)
+		}
+	}
+}
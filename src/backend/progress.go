@@ -0,0 +1,146 @@
+package main
+
+import (
+	"net/http"
+	"recipe-finder/graph"
+	"recipe-finder/model"
+	"strconv"
+	"sync/atomic"
+
+	"golang.org/x/net/websocket"
+)
+
+// progressDone is the last message sent over the progress socket
+type progressDone struct {
+	Type        string      `json:"type"`
+	Duration    float64     `json:"duration"`
+	VisitedNode int         `json:"visitedNode"`
+	Truncated   bool        `json:"truncated"`
+	Steps       []int       `json:"steps,omitempty"`
+	Budget      string      `json:"budget,omitempty"`
+	Pruned      int         `json:"pruned,omitempty"`
+	Stats       model.Stats `json:"stats"`
+}
+
+// progressError is sent right before done when the search timed out
+type progressError struct {
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// handleRecipeProgress upgrades to a WebSocket and sends the search steps
+// as they happen. The found events of a deterministic search come last,
+// in their sorted order. The query takes the same fields as RecipeRequest plus
+// sample, which only forwards every nth expand and enqueue event.
+func (s *server) handleRecipeProgress(w http.ResponseWriter, r *http.Request) {
+	req, apiErr := recipeRequestFromQuery(r)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	g, apiErr := s.graphFor(&req.Dataset)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	if apiErr := validateRequest(g, &req); apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	sample := 1
+	if raw := r.URL.Query().Get("sample"); raw != "" {
+		var err error
+		sample, err = strconv.Atoi(raw)
+		if err != nil || sample < 1 {
+			writeError(w, newAPIError(http.StatusBadRequest, codeInvalidRequest, "sample must be a positive integer"))
+			return
+		}
+	}
+
+	// Tanpa Handshake, origin tidak dicek sama seperti CORS "*" di endpoint lain
+	websocket.Server{Handler: func(ws *websocket.Conn) {
+		s.streamProgress(ws, g, req, sample)
+	}}.ServeHTTP(w, r)
+}
+
+func (s *server) streamProgress(ws *websocket.Conn, g *graph.RecipeGraph, req RecipeRequest, sample int) {
+	defer ws.Close()
+
+	ctx, cancel := s.searchContext(ws.Request())
+	defer cancel()
+
+	// Pesan dari client tidak dipakai, dibaca hanya untuk tahu kapan koneksi ditutup
+	go func() {
+		var discard string
+		for websocket.Message.Receive(ws, &discard) == nil {
+		}
+		cancel()
+	}()
+
+	events := make(chan model.Event, 256)
+	var frontier atomic.Int64
+	sampled := model.SampleProgress(sample, func(event model.Event) {
+		select {
+		case events <- event:
+		default:
+			// Client tidak bisa mengikuti, event progress boleh dibuang
+		}
+	})
+
+	opts := model.Options{
+		OnProgress: func(event model.Event) {
+			frontier.Store(int64(event.Frontier))
+			sampled(event)
+		},
+	}
+	if !holdsRecipes(req) {
+		opts.OnRecipe = func(recipe map[string][]string) {
+			event := model.Event{Type: model.EventFound, Result: recipe, Frontier: int(frontier.Load())}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+			}
+		}
+	}
+
+	finished := make(chan model.Result, 1)
+	go func() {
+		// Tidak lewat cache karena tujuannya melihat jalannya pencarian
+		finished <- exploreRecipes(ctx, g, req, s.searchOptions(req, opts))
+	}()
+
+	for {
+		select {
+		case event := <-events:
+			if err := websocket.JSON.Send(ws, event); err != nil {
+				cancel()
+			}
+		case result := <-finished:
+			for len(events) > 0 {
+				websocket.JSON.Send(ws, <-events)
+			}
+			if holdsRecipes(req) {
+				// Pencarian deterministik mengirim resepnya setelah diurutkan
+				for _, recipe := range result.Recipes {
+					websocket.JSON.Send(ws, model.Event{Type: model.EventFound, Result: recipe})
+				}
+			}
+			if err := timeoutError(ctx, req, result); err != nil {
+				websocket.JSON.Send(ws, progressError{Type: "error", Code: err.Code, Message: err.Message})
+			}
+			websocket.JSON.Send(ws, progressDone{
+				Type:        "done",
+				Duration:    result.Duration,
+				VisitedNode: result.VisitedNode,
+				Truncated:   result.Truncated,
+				Steps:       result.Steps,
+				Budget:      result.Budget,
+				Pruned:      result.Pruned,
+				Stats:       result.Stats,
+			})
+			return
+		}
+	}
+}
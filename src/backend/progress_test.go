@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"recipe-finder/model"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+// progressMessage holds the fields of every message on the progress socket
+type progressMessage struct {
+	Type     string              `json:"type"`
+	Element  string              `json:"element"`
+	Frontier int                 `json:"frontier"`
+	Result   map[string][]string `json:"result"`
+	Code     string              `json:"code"`
+	Stats    model.Stats         `json:"stats"`
+}
+
+// dialProgress opens /api/recipe/progress with query and reads every
+// message up to and including done
+func dialProgress(t *testing.T, s *server, query url.Values) []progressMessage {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(s.handleRecipeProgress))
+	defer ts.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/?"+query.Encode(), "", ts.URL)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer ws.Close()
+
+	var messages []progressMessage
+	for {
+		var message progressMessage
+		if err := websocket.JSON.Receive(ws, &message); err != nil {
+			t.Fatalf("receive after %d messages: %v", len(messages), err)
+		}
+		messages = append(messages, message)
+		if message.Type == "done" {
+			return messages
+		}
+	}
+}
+
+func TestRecipeProgressDeterministicOrder(t *testing.T) {
+	s := newSmallServer()
+	for _, algorithm := range []string{"bfs", "dfs", "bidirectional"} {
+		req := RecipeRequest{Element: "Rain", Algorithm: algorithm, MaxRecipe: 10, Deterministic: true}
+		var want RecipeResponse
+		postRecipe(t, s, req, &want)
+
+		query := url.Values{"element": {"Rain"}, "algorithm": {algorithm}, "maxRecipe": {"10"}, "deterministic": {"true"}}
+		var got []map[string][]string
+		for _, message := range dialProgress(t, s, query) {
+			if message.Type == "found" {
+				got = append(got, message.Result)
+			}
+		}
+		if !reflect.DeepEqual(got, decodeRecipes(want.Results)) {
+			t.Errorf("%s: found events %v, want %v", algorithm, got, want.Results)
+		}
+	}
+}
+
+func TestRecipeProgress(t *testing.T) {
+	s := newSmallServer()
+	tests := []struct {
+		name   string
+		query  url.Values
+		found  int
+		sample int
+	}{
+		{"bfs", url.Values{"element": {"Rain"}, "algorithm": {"bfs"}, "maxRecipe": {"10"}}, 6, 1},
+		{"dfs", url.Values{"element": {"Rain"}, "algorithm": {"dfs"}, "maxRecipe": {"2"}}, 2, 1},
+		{"bidirectional", url.Values{"element": {"Rain"}, "algorithm": {"bidirectional"}, "maxRecipe": {"10"}}, 6, 1},
+		// Event found selalu dikirim walaupun expand dan enqueue disampel
+		{"sampled", url.Values{"element": {"Rain"}, "algorithm": {"bfs"}, "maxRecipe": {"10"}, "sample": {"3"}}, 6, 3},
+	}
+
+	counts := map[string]int{}
+	for _, tt := range tests {
+		messages := dialProgress(t, s, tt.query)
+		steps, found := 0, 0
+		for _, message := range messages[:len(messages)-1] {
+			switch message.Type {
+			case model.EventExpand, model.EventEnqueue:
+				steps++
+			case model.EventFound:
+				found++
+			default:
+				t.Errorf("%s: unexpected %s message before done", tt.name, message.Type)
+			}
+		}
+		if found != tt.found {
+			t.Errorf("%s: %d found events, want %d", tt.name, found, tt.found)
+		}
+		done := messages[len(messages)-1]
+		if done.Stats.Expanded == 0 {
+			t.Errorf("%s: done has no stats", tt.name)
+		}
+		if tt.sample == 1 && steps == 0 {
+			t.Errorf("%s: no expand or enqueue events", tt.name)
+		}
+		counts[tt.name] = steps
+	}
+	if want := counts["bfs"] / 3; counts["sampled"] != want {
+		t.Errorf("sample=3 sent %d of %d steps, want %d", counts["sampled"], counts["bfs"], want)
+	}
+}
+
+func TestRecipeProgressInvalid(t *testing.T) {
+	s := newSmallServer()
+	tests := []struct {
+		query  string
+		status int
+		code   string
+	}{
+		{"element=Rain&algorithm=bfs&maxRecipe=1&sample=0", http.StatusBadRequest, codeInvalidRequest},
+		{"element=Rain&algorithm=bfs&maxRecipe=1&sample=x", http.StatusBadRequest, codeInvalidRequest},
+		{"element=Rian&algorithm=bfs&maxRecipe=1", http.StatusNotFound, codeUnknownElement},
+		{"element=Rain&algorithm=bfs&maxRecipe=1&dataset=la9", http.StatusNotFound, codeUnknownDataset},
+	}
+
+	for _, tt := range tests {
+		// Request yang salah ditolak sebelum upgrade ke WebSocket
+		rec := httptest.NewRecorder()
+		s.handleRecipeProgress(rec, httptest.NewRequest(http.MethodGet, "/api/recipe/progress?"+tt.query, nil))
+		var body struct{ Error apiError }
+		json.NewDecoder(rec.Body).Decode(&body)
+		if rec.Code != tt.status || body.Error.Code != tt.code {
+			t.Errorf("%s: %d %s, want %d %s", tt.query, rec.Code, body.Error.Code, tt.status, tt.code)
+		}
+	}
+}
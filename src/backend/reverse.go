@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"recipe-finder/search"
+	"strings"
+)
+
+type ReachableRequest struct {
+	Owned []string `json:"owned"`
+	// Steps is the maximum number of combination rounds, 0 means no limit
+	Steps int `json:"steps"`
+	// Dataset names the recipe data to use, empty uses the default
+	Dataset string `json:"dataset"`
+}
+
+type usesResponse struct {
+	Element string       `json:"element"`
+	Uses    []search.Use `json:"uses"`
+}
+
+type reachableResponse struct {
+	Results []search.Reach `json:"results"`
+}
+
+// handleElementUses answers "what can I make with this element"
+func (s *server) handleElementUses(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	name := strings.TrimSpace(r.PathValue("name"))
+	dataset := r.URL.Query().Get("dataset")
+	g, apiErr := s.graphFor(&dataset)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	if !g.Has(name) {
+		err := newAPIError(http.StatusNotFound, codeUnknownElement, "unknown element %q", name)
+		err.Suggestions = g.Suggest(name, 5)
+		writeError(w, err)
+		return
+	}
+
+	response := usesResponse{Element: name, Uses: search.UsedIn(g, name)}
+	if response.Uses == nil {
+		response.Uses = []search.Use{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleReachable lists everything that can be made from a set of owned elements
+func (s *server) handleReachable(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	var req ReachableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, newAPIError(http.StatusBadRequest, codeInvalidRequest, "invalid request body: %v", err))
+		return
+	}
+	if len(req.Owned) == 0 {
+		writeError(w, newAPIError(http.StatusBadRequest, codeInvalidRequest, "owned must contain at least one element"))
+		return
+	}
+	if req.Steps < 0 {
+		writeError(w, newAPIError(http.StatusBadRequest, codeInvalidLimit, "steps must not be negative"))
+		return
+	}
+	g, apiErr := s.graphFor(&req.Dataset)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	for i, name := range req.Owned {
+		req.Owned[i] = strings.TrimSpace(name)
+		if !g.Has(req.Owned[i]) {
+			err := newAPIError(http.StatusNotFound, codeUnknownElement, "unknown element %q", name)
+			err.Suggestions = g.Suggest(name, 5)
+			writeError(w, err)
+			return
+		}
+	}
+
+	response := reachableResponse{Results: search.Reachable(g, req.Owned, req.Steps)}
+	if response.Results == nil {
+		response.Results = []search.Reach{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,147 @@
+package scrape
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// TierChange is an element whose tier differs between two data sets
+type TierChange struct {
+	Element string `json:"element"`
+	Old     int    `json:"old"`
+	New     int    `json:"new"`
+}
+
+// RecipeChange lists the recipes of one element that only exist on one side
+type RecipeChange struct {
+	Element string     `json:"element"`
+	Added   [][]string `json:"added,omitempty"`
+	Removed [][]string `json:"removed,omitempty"`
+}
+
+// DataDiff is the difference between two recipe data sets, every list is
+// sorted by element name
+type DataDiff struct {
+	AddedElements   []string       `json:"addedElements"`
+	RemovedElements []string       `json:"removedElements"`
+	TierChanges     []TierChange   `json:"tierChanges"`
+	RecipeChanges   []RecipeChange `json:"recipeChanges"`
+}
+
+// ReadJson loads recipe data exported by WriteJson
+func ReadJson(path string) (map[string]ElementData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	elements := make(map[string]ElementData)
+	if err := json.NewDecoder(file).Decode(&elements); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return elements, nil
+}
+
+// Diff compares the current data with a new one. Recipes are compared
+// regardless of the order of their ingredients.
+func Diff(current, next map[string]ElementData) DataDiff {
+	diff := DataDiff{
+		AddedElements:   []string{},
+		RemovedElements: []string{},
+		TierChanges:     []TierChange{},
+		RecipeChanges:   []RecipeChange{},
+	}
+
+	for _, name := range sortedNames(current) {
+		if _, ok := next[name]; !ok {
+			diff.RemovedElements = append(diff.RemovedElements, name)
+		}
+	}
+	for _, name := range sortedNames(next) {
+		el := next[name]
+		old, ok := current[name]
+		if !ok {
+			diff.AddedElements = append(diff.AddedElements, name)
+			continue
+		}
+		if old.Tier != el.Tier {
+			diff.TierChanges = append(diff.TierChanges, TierChange{Element: name, Old: old.Tier, New: el.Tier})
+		}
+
+		change := RecipeChange{
+			Element: name,
+			Added:   missingRecipes(el.Recipes, old.Recipes),
+			Removed: missingRecipes(old.Recipes, el.Recipes),
+		}
+		if len(change.Added) > 0 || len(change.Removed) > 0 {
+			diff.RecipeChanges = append(diff.RecipeChanges, change)
+		}
+	}
+	return diff
+}
+
+// Empty reports whether both data sets are the same
+func (d DataDiff) Empty() bool {
+	return len(d.AddedElements) == 0 && len(d.RemovedElements) == 0 &&
+		len(d.TierChanges) == 0 && len(d.RecipeChanges) == 0
+}
+
+// WriteText writes the diff in a human readable form, one line per change
+func (d DataDiff) WriteText(w io.Writer) {
+	if d.Empty() {
+		fmt.Fprintln(w, "no changes")
+		return
+	}
+	for _, name := range d.AddedElements {
+		fmt.Fprintf(w, "+ element %s\n", name)
+	}
+	for _, name := range d.RemovedElements {
+		fmt.Fprintf(w, "- element %s\n", name)
+	}
+	for _, change := range d.TierChanges {
+		fmt.Fprintf(w, "~ tier %s: %d -> %d\n", change.Element, change.Old, change.New)
+	}
+	for _, change := range d.RecipeChanges {
+		for _, recipe := range change.Added {
+			fmt.Fprintf(w, "+ recipe %s = %s\n", change.Element, strings.Join(recipe, " + "))
+		}
+		for _, recipe := range change.Removed {
+			fmt.Fprintf(w, "- recipe %s = %s\n", change.Element, strings.Join(recipe, " + "))
+		}
+	}
+}
+
+// missingRecipes returns the recipes of from that are not in other
+func missingRecipes(from, other [][]string) [][]string {
+	seen := make(map[string]bool, len(other))
+	for _, recipe := range other {
+		seen[recipeKey(recipe)] = true
+	}
+	var missing [][]string
+	for _, recipe := range from {
+		if !seen[recipeKey(recipe)] {
+			missing = append(missing, recipe)
+		}
+	}
+	return missing
+}
+
+func recipeKey(recipe []string) string {
+	ingredients := append([]string(nil), recipe...)
+	sort.Strings(ingredients)
+	return strings.Join(ingredients, "+")
+}
+
+func sortedNames(elements map[string]ElementData) []string {
+	names := make([]string, 0, len(elements))
+	for name := range elements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,60 @@
+package scrape
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	current := map[string]ElementData{
+		"Air":   {Tier: 0},
+		"Water": {Tier: 0},
+		"Mud":   {Tier: 1, Recipes: [][]string{{"Earth", "Water"}}},
+		"Steam": {Tier: 1, Recipes: [][]string{{"Air", "Water"}, {"Fire", "Water"}}},
+		"Cloud": {Tier: 2, Recipes: [][]string{{"Air", "Steam"}}},
+	}
+
+	tests := []struct {
+		name string
+		next map[string]ElementData
+		want DataDiff
+	}{
+		{"same", current, DataDiff{}},
+		{"ingredient order", map[string]ElementData{
+			"Air":   {Tier: 0},
+			"Water": {Tier: 0},
+			"Mud":   {Tier: 1, Recipes: [][]string{{"Water", "Earth"}}},
+			"Steam": {Tier: 1, Recipes: [][]string{{"Water", "Fire"}, {"Water", "Air"}}},
+			"Cloud": {Tier: 2, Recipes: [][]string{{"Steam", "Air"}}},
+		}, DataDiff{}},
+		{"changes", map[string]ElementData{
+			"Air":   {Tier: 0},
+			"Water": {Tier: 0},
+			"Fire":  {Tier: 0},
+			"Steam": {Tier: 1, Recipes: [][]string{{"Air", "Water"}, {"Air", "Fire"}}},
+			"Cloud": {Tier: 3, Recipes: [][]string{{"Air", "Steam"}}},
+		}, DataDiff{
+			AddedElements:   []string{"Fire"},
+			RemovedElements: []string{"Mud"},
+			TierChanges:     []TierChange{{Element: "Cloud", Old: 2, New: 3}},
+			RecipeChanges: []RecipeChange{{
+				Element: "Steam",
+				Added:   [][]string{{"Air", "Fire"}},
+				Removed: [][]string{{"Fire", "Water"}},
+			}},
+		}},
+	}
+
+	for _, tt := range tests {
+		got := Diff(current, tt.next)
+		if got.Empty() != tt.want.Empty() {
+			t.Errorf("%s: Diff(...).Empty() = %v, want %v", tt.name, got.Empty(), tt.want.Empty())
+		}
+		if tt.want.Empty() {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Diff =\n%+v\nwant\n%+v", tt.name, got, tt.want)
+		}
+	}
+}
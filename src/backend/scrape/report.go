@@ -0,0 +1,75 @@
+package scrape
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Reasons a recipe is dropped by CleanRecipesWithReport
+const (
+	// ReasonMissingIngredient: the ingredient is not an element of the data
+	ReasonMissingIngredient = "missing_ingredient"
+	// ReasonTierNotLower: the ingredient is not from a lower tier than the
+	// element, so the recipe could loop
+	ReasonTierNotLower = "tier_not_lower"
+	// ReasonUnreachableIngredient: the ingredient has no recipe left
+	ReasonUnreachableIngredient = "unreachable_ingredient"
+)
+
+// DroppedRecipe is a recipe removed while cleaning. Ingredient is the one
+// that caused it.
+type DroppedRecipe struct {
+	Element    string   `json:"element"`
+	Recipe     []string `json:"recipe"`
+	Reason     string   `json:"reason"`
+	Ingredient string   `json:"ingredient"`
+}
+
+// DroppedElement is an element left without any recipe after cleaning. It
+// stays in the data but cannot be made from the base elements.
+type DroppedElement struct {
+	Element string `json:"element"`
+	Tier    int    `json:"tier"`
+}
+
+// CleanReport lists everything CleanRecipesWithReport dropped, sorted by
+// element name
+type CleanReport struct {
+	Recipes  []DroppedRecipe  `json:"recipes"`
+	Elements []DroppedElement `json:"elements"`
+}
+
+func (r *CleanReport) dropRecipe(element string, recipe []string, reason, ingredient string) {
+	r.Recipes = append(r.Recipes, DroppedRecipe{Element: element, Recipe: recipe, Reason: reason, Ingredient: ingredient})
+}
+
+func (r *CleanReport) dropElement(element string, tier int) {
+	r.Elements = append(r.Elements, DroppedElement{Element: element, Tier: tier})
+}
+
+// sort orders the report so two runs over the same data are identical
+func (r *CleanReport) sort() {
+	sort.Slice(r.Recipes, func(i, j int) bool {
+		a, b := r.Recipes[i], r.Recipes[j]
+		if a.Element != b.Element {
+			return a.Element < b.Element
+		}
+		return strings.Join(a.Recipe, "+") < strings.Join(b.Recipe, "+")
+	})
+	sort.Slice(r.Elements, func(i, j int) bool {
+		return r.Elements[i].Element < r.Elements[j].Element
+	})
+}
+
+// WriteText writes the report in a human readable form, one line per entry
+func (r *CleanReport) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "%d recipe(s) dropped, %d element(s) left without a recipe\n", len(r.Recipes), len(r.Elements))
+	for _, dropped := range r.Recipes {
+		fmt.Fprintf(w, "recipe  %s = %s: %s (%s)\n", dropped.Element, strings.Join(dropped.Recipe, " + "), dropped.Reason, dropped.Ingredient)
+	}
+	for _, dropped := range r.Elements {
+		fmt.Fprintf(w, "element %s (tier %d): no valid recipe\n", dropped.Element, dropped.Tier)
+	}
+}
@@ -0,0 +1,61 @@
+package scrape
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCleanRecipesWithReport(t *testing.T) {
+	data := map[string]ElementData{
+		"Air":   {Tier: 0},
+		"Water": {Tier: 0},
+		"Time":  {Special: true, Unlock: "Discover 100 elements."},
+		"Steam": {Tier: 1, Recipes: [][]string{{"Air", "Water"}, {"Air", "Ghost"}}},
+		"Loop":  {Tier: 1, Recipes: [][]string{{"Steam", "Air"}}},
+		"Cloud": {Tier: 2, Recipes: [][]string{{"Loop", "Air"}, {"Steam", "Air"}, {"Steam", "Time"}}},
+		"Storm": {Tier: 3, Recipes: [][]string{{"Loop", "Loop"}}},
+	}
+
+	cleaned, report := CleanRecipesWithReport(data)
+
+	wantRecipes := map[string][][]string{
+		"Air":   nil,
+		"Water": nil,
+		"Time":  nil,
+		"Steam": {{"Air", "Water"}},
+		"Loop":  nil,
+		"Cloud": {{"Steam", "Air"}, {"Steam", "Time"}},
+		"Storm": nil,
+	}
+	if len(cleaned) != len(wantRecipes) {
+		t.Errorf("cleaned data has %d elements, want %d", len(cleaned), len(wantRecipes))
+	}
+	for name, want := range wantRecipes {
+		if got := cleaned[name].Recipes; !reflect.DeepEqual(got, want) {
+			t.Errorf("recipes of %s = %v, want %v", name, got, want)
+		}
+	}
+	if !cleaned["Time"].Special || cleaned["Storm"].Tier != 3 {
+		t.Errorf("cleaning changed the elements themselves: %+v %+v", cleaned["Time"], cleaned["Storm"])
+	}
+
+	wantDropped := []DroppedRecipe{
+		{Element: "Cloud", Recipe: []string{"Loop", "Air"}, Reason: ReasonUnreachableIngredient, Ingredient: "Loop"},
+		{Element: "Loop", Recipe: []string{"Steam", "Air"}, Reason: ReasonTierNotLower, Ingredient: "Steam"},
+		{Element: "Steam", Recipe: []string{"Air", "Ghost"}, Reason: ReasonMissingIngredient, Ingredient: "Ghost"},
+		{Element: "Storm", Recipe: []string{"Loop", "Loop"}, Reason: ReasonUnreachableIngredient, Ingredient: "Loop"},
+	}
+	if !reflect.DeepEqual(report.Recipes, wantDropped) {
+		t.Errorf("dropped recipes =\n%+v\nwant\n%+v", report.Recipes, wantDropped)
+	}
+	wantElements := []DroppedElement{{Element: "Loop", Tier: 1}, {Element: "Storm", Tier: 3}}
+	if !reflect.DeepEqual(report.Elements, wantElements) {
+		t.Errorf("dropped elements = %+v, want %+v", report.Elements, wantElements)
+	}
+
+	// Data yang sudah bersih tidak berubah lagi
+	again, report := CleanRecipesWithReport(cleaned)
+	if !reflect.DeepEqual(again, cleaned) || len(report.Recipes) != 0 {
+		t.Errorf("cleaning twice dropped %+v", report.Recipes)
+	}
+}
@@ -3,10 +3,10 @@ package scrape
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
 	"os"
-	"strconv"
+	"path/filepath"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -15,38 +15,74 @@ import (
 type ElementData struct {
 	Tier    int        `json:"tier"`
 	Recipes [][]string `json:"recipes"`
+	// Special elements are unlocked by the condition in Unlock instead of
+	// a recipe
+	Special bool   `json:"special,omitempty"`
+	Unlock  string `json:"unlock,omitempty"`
 }
 
-func CompleteScrapeRecipes() map[string]ElementData {
-	elements := make(map[string]ElementData)
+// DefaultURL is the Little Alchemy 2 element list on the fandom wiki
+const DefaultURL = "https://little-alchemy.fandom.com/wiki/Elements_(Little_Alchemy_2)"
+
+// DefaultDataPath is where the cleaned recipes are exported to and loaded from
+const DefaultDataPath = "./data/recipes_complete.json"
+
+// CompleteScrapeRecipes fetches the live Little Alchemy 2 wiki page and parses every element table on it
+func CompleteScrapeRecipes() (map[string]ElementData, error) {
+	return LittleAlchemy2.Scrape()
+}
+
+// ScrapeRecipesFromFile parses a saved HTML snapshot of the Little Alchemy 2 wiki page
+func ScrapeRecipesFromFile(path string) (map[string]ElementData, error) {
+	return LittleAlchemy2.ScrapeFile(path)
+}
+
+// ScrapeRecipesFromReader parses the Little Alchemy 2 element tables from any HTML source
+func ScrapeRecipesFromReader(r io.Reader) (map[string]ElementData, error) {
+	return LittleAlchemy2.ScrapeReader(r)
+}
 
+// Scrape fetches the live wiki page of the source and parses every element table on it
+func (src Source) Scrape() (map[string]ElementData, error) {
 	// Request HTML page
-	res, err := http.Get("https://little-alchemy.fandom.com/wiki/Elements_(Little_Alchemy_2)")
+	res, err := http.Get(src.URL)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer res.Body.Close()
 	if res.StatusCode != 200 {
-		log.Fatalf("status code error: %d %s", res.StatusCode, res.Status)
+		return nil, fmt.Errorf("status code error: %d %s", res.StatusCode, res.Status)
+	}
+
+	return src.ScrapeReader(res.Body)
+}
+
+// ScrapeFile parses a saved HTML snapshot of the source's wiki page
+func (src Source) ScrapeFile(path string) (map[string]ElementData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
+
+	return src.ScrapeReader(file)
+}
+
+// ScrapeReader parses the element tables of the source from any HTML source
+func (src Source) ScrapeReader(r io.Reader) (map[string]ElementData, error) {
+	elements := make(map[string]ElementData)
 
 	// Load HTML doc
-	doc, err := goquery.NewDocumentFromReader(res.Body)
+	doc, err := goquery.NewDocumentFromReader(r)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	doc.Find("h3").Each(func(i int, elementTier *goquery.Selection) {
 		spanheadline := elementTier.Find("span.mw-headline")
-		if spanheadline.Length() > 0 && spanheadline.Text() != "Special element" {
-			elementTierText := spanheadline.Text()
-			// Extract angka tier
-			tierStr := strings.TrimSuffix(strings.TrimPrefix(elementTierText, "Tier "), " elements")
-			tier, err := strconv.Atoi(tierStr)
-			if err != nil {
-				tier = 0 // Parsing gagal -> elemen dasar -> tier 0
-			}
-
+		special := src.SpecialSection != "" && spanheadline.Text() == src.SpecialSection
+		tier, ok := src.ParseTier(spanheadline.Text())
+		if spanheadline.Length() > 0 && (ok || special) {
 			elementTier.NextAllFiltered("table.list-table").First().Each(func(j int, tableSelection *goquery.Selection) {
 				tableSelection.Find("tr").Each(func(j int, rowSelection *goquery.Selection) {
 					// Skip header rows
@@ -60,7 +96,16 @@ func CompleteScrapeRecipes() map[string]ElementData {
 					// Kolom 1 nama elemen
 					// Perlu last karena nama elemen ada di dalam <a> yang terakhir
 					elementName := columns.First().Find("a").Last().Text()
-					if elementName == "" || elementName == "Time" || elementName == "Ruins" {
+					if elementName == "" || src.excluded(elementName) {
+						return
+					}
+
+					// Elemen spesial tidak punya resep, kolom terakhir berisi syarat unlock
+					if special {
+						elements[elementName] = ElementData{
+							Special: true,
+							Unlock:  strings.TrimSpace(columns.Last().Text()),
+						}
 						return
 					}
 
@@ -70,20 +115,20 @@ func CompleteScrapeRecipes() map[string]ElementData {
 					columns.Last().Find("li").Each(func(k int, recipeSelection *goquery.Selection) {
 						var ingredients []string
 
-						hasTimeOrRuins := false
+						hasExcluded := false
 
 						recipeSelection.Find("a").Each(func(l int, ingredientSelection *goquery.Selection) {
 							ingredientName := ingredientSelection.Text()
 							if ingredientName != "" {
 								ingredients = append(ingredients, ingredientName)
 
-								if ingredientName == "Time" || ingredientName == "Ruins" {
-									hasTimeOrRuins = true
+								if src.excluded(ingredientName) {
+									hasExcluded = true
 								}
 							}
 						})
 
-						if len(ingredients) > 0 && !hasTimeOrRuins {
+						if len(ingredients) > 0 && !hasExcluded {
 							validRecipes = append(validRecipes, ingredients)
 						}
 					})
@@ -98,32 +143,71 @@ func CompleteScrapeRecipes() map[string]ElementData {
 		}
 	})
 
-	return elements
+	if src.DeriveTiers {
+		AssignTiers(elements)
+	}
+	return elements, nil
 }
 
-func ScrapeToJsonComplete() {
-	elements := CompleteScrapeRecipes()
-	elements = CleanRecipes(elements)
+// ScrapeToJsonComplete scrapes, cleans and exports the Little Alchemy 2 recipes to DefaultDataPath.
+// If htmlPath is not empty the saved snapshot is parsed instead of the live wiki.
+func ScrapeToJsonComplete(htmlPath string) error {
+	return LittleAlchemy2.ScrapeToJson(htmlPath, DefaultDataPath)
+}
 
-	jsonData, err := json.MarshalIndent(elements, "", "  ")
+// ScrapeToJson scrapes, cleans and exports the recipes of the source to path.
+// If htmlPath is not empty the saved snapshot is parsed instead of the live wiki.
+func (src Source) ScrapeToJson(htmlPath, path string) error {
+	var elements map[string]ElementData
+	var err error
+	if htmlPath != "" {
+		elements, err = src.ScrapeFile(htmlPath)
+	} else {
+		elements, err = src.Scrape()
+	}
 	if err != nil {
-		log.Fatalf("Error marshalling JSON: %v", err)
+		return fmt.Errorf("scraping recipes: %w", err)
 	}
-	if _, err := os.Stat("./data"); os.IsNotExist(err) {
-		err = os.Mkdir("./data", 0755)
-		if err != nil {
-			log.Fatalf("Error creating directory: %v", err)
-		}
+	elements, report := CleanRecipesWithReport(elements)
+	fmt.Printf("Cleaning dropped %d recipe(s), %d element(s) left without a recipe\n", len(report.Recipes), len(report.Elements))
+
+	if err := WriteJson(elements, path); err != nil {
+		return err
 	}
-	err = os.WriteFile("./data/recipes_complete.json", jsonData, 0644)
+
+	fmt.Println("Successfully exported recipes to", path)
+	return nil
+}
+
+// WriteJson exports the elements to path, creating its directory when needed
+func WriteJson(elements map[string]ElementData, path string) error {
+	jsonData, err := json.MarshalIndent(elements, "", "  ")
 	if err != nil {
-		log.Fatalf("Error writing to file: %v", err)
+		return fmt.Errorf("marshalling JSON: %w", err)
 	}
-
-	fmt.Println("Successfully exported recipes to data/recipes_complete.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+		return fmt.Errorf("writing to file: %w", err)
+	}
+	return nil
 }
 
+// CleanRecipes drops every recipe the searchers cannot use, see
+// CleanRecipesWithReport for what is dropped and why
 func CleanRecipes(itemsMap map[string]ElementData) map[string]ElementData {
+	cleaned, _ := CleanRecipesWithReport(itemsMap)
+	return cleaned
+}
+
+// CleanRecipesWithReport drops recipes with an unknown ingredient, recipes
+// with an ingredient that is not from a lower tier, and recipes that depend
+// on an element which can no longer be made. The report lists each of them
+// together with the elements left without any recipe.
+func CleanRecipesWithReport(itemsMap map[string]ElementData) (map[string]ElementData, *CleanReport) {
+	report := &CleanReport{Recipes: []DroppedRecipe{}, Elements: []DroppedElement{}}
+
 	// Create a new map for the cleaned data
 	cleanedMap := make(map[string]ElementData)
 
@@ -149,7 +233,7 @@ func CleanRecipes(itemsMap map[string]ElementData) map[string]ElementData {
 			for _, ingredient := range recipe {
 				if _, exists := itemsMap[ingredient]; !exists {
 					valid = false
-					// fmt.Printf("Removing recipe for %s: ingredient %s doesn't exist\n", itemName, ingredient)
+					report.dropRecipe(itemName, recipe, ReasonMissingIngredient, ingredient)
 					break
 				}
 			}
@@ -165,12 +249,13 @@ func CleanRecipes(itemsMap map[string]ElementData) map[string]ElementData {
 		cleanedMap[itemName] = itemData
 	}
 	for itemName := range cleanedMap {
-		cleanedMap[itemName] = filterRecipes(cleanedMap, itemName)
+		cleanedMap[itemName] = filterRecipes(cleanedMap, itemName, report)
 	}
-	cleanedMap = removeAllInvalidRecipes(cleanedMap)
-	return cleanedMap
+	cleanedMap = removeAllInvalidRecipes(cleanedMap, report)
+	report.sort()
+	return cleanedMap, report
 }
-func filterRecipes(itemsMap map[string]ElementData, name string) ElementData {
+func filterRecipes(itemsMap map[string]ElementData, name string, report *CleanReport) ElementData {
 	element := itemsMap[name]
 	var filtered [][]string
 
@@ -179,12 +264,12 @@ func filterRecipes(itemsMap map[string]ElementData, name string) ElementData {
 		for _, ing := range recipe {
 			ingData, ok := itemsMap[ing]
 			if !ok {
-				// fmt.Printf("Bahan tidak ditemukan: %s\n", ing)
+				report.dropRecipe(name, recipe, ReasonMissingIngredient, ing)
 				valid = false
 				break
 			}
 			if ingData.Tier >= element.Tier {
-				// fmt.Printf("Bahan %s dengan Tier %d tidak valid untuk %s (Tier %d)\n", ing, ingData.Tier, name, element.Tier)
+				report.dropRecipe(name, recipe, ReasonTierNotLower, ing)
 				valid = false
 				break
 			}
@@ -198,7 +283,7 @@ func filterRecipes(itemsMap map[string]ElementData, name string) ElementData {
 	element.Recipes = filtered
 	return element
 }
-func removeAllInvalidRecipes(itemsMap map[string]ElementData) map[string]ElementData {
+func removeAllInvalidRecipes(itemsMap map[string]ElementData, report *CleanReport) map[string]ElementData {
 	// Set untuk menyimpan elemen-elemen yang invalid
 	invalidElements := make(map[string]bool)
 
@@ -206,6 +291,7 @@ func removeAllInvalidRecipes(itemsMap map[string]ElementData) map[string]Element
 	for name, el := range itemsMap {
 		if (el.Recipes == nil || len(el.Recipes) == 0) && el.Tier > 0 {
 			invalidElements[name] = true
+			report.dropElement(name, el.Tier)
 		}
 	}
 
@@ -220,6 +306,7 @@ func removeAllInvalidRecipes(itemsMap map[string]ElementData) map[string]Element
 				for _, ing := range recipe {
 					if invalidElements[ing] {
 						invalid = true
+						report.dropRecipe(name, recipe, ReasonUnreachableIngredient, ing)
 						break
 					}
 				}
@@ -233,6 +320,7 @@ func removeAllInvalidRecipes(itemsMap map[string]ElementData) map[string]Element
 				itemsMap[name] = el
 				if len(filtered) == 0 && !invalidElements[name] {
 					invalidElements[name] = true
+					report.dropElement(name, el.Tier)
 					changed = true
 				}
 			}
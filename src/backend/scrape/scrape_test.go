@@ -0,0 +1,58 @@
+package scrape
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestScrapeReader(t *testing.T) {
+	tests := []struct {
+		name string
+		src  Source
+		file string
+		want map[string]ElementData
+	}{
+		{"la2", LittleAlchemy2, "testdata/la2.html", map[string]ElementData{
+			"Air":   {Tier: 0},
+			"Fire":  {Tier: 0},
+			"Water": {Tier: 0},
+			"Time":  {Special: true, Unlock: "Discover 100 elements."},
+			"Steam": {Tier: 1, Recipes: [][]string{{"Air", "Water"}, {"Fire", "Water"}}},
+			"Cloud": {Tier: 2, Recipes: [][]string{{"Air", "Steam"}, {"Steam", "Time"}}},
+		}},
+		{"la2 excluded", Source{ParseTier: parseTierHeading, SpecialSection: "Special element", Excluded: []string{"Time", "Fire"}}, "testdata/la2.html", map[string]ElementData{
+			"Air":   {Tier: 0},
+			"Water": {Tier: 0},
+			"Steam": {Tier: 1, Recipes: [][]string{{"Air", "Water"}}},
+			"Cloud": {Tier: 2, Recipes: [][]string{{"Air", "Steam"}}},
+		}},
+		// Halaman LA1 tidak dikelompokkan per tier, tier dihitung dari resep
+		{"la1", LittleAlchemy1, "testdata/la1.html", map[string]ElementData{
+			"Air":   {Tier: 0},
+			"Earth": {Tier: 0},
+			"Fire":  {Tier: 0},
+			"Water": {Tier: 0},
+			"Lava":  {Tier: 1, Recipes: [][]string{{"Earth", "Fire"}}},
+			"Mud":   {Tier: 1, Recipes: [][]string{{"Earth", "Water"}}},
+			"Stone": {Tier: 2, Recipes: [][]string{{"Air", "Lava"}}},
+			"Brick": {Tier: 2, Recipes: [][]string{{"Fire", "Mud"}, {"Clay", "Fire"}}},
+		}},
+	}
+
+	for _, tt := range tests {
+		file, err := os.Open(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := tt.src.ScrapeReader(file)
+		file.Close()
+		if err != nil {
+			t.Errorf("%s: ScrapeReader failed: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ScrapeReader =\n%v\nwant\n%v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package scrape
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Source describes where a recipe dataset is scraped from and how its wiki
+// page is laid out. Every source uses the fandom element tables: an h3
+// heading per section followed by a list-table whose rows hold the element
+// name and a list of recipes.
+type Source struct {
+	// Name identifies the dataset, e.g. in RecipeRequest.Dataset
+	Name string
+	URL  string
+	// DataPath is where the cleaned recipes are exported to by default
+	DataPath string
+	// ParseTier turns a section heading into the tier of its elements,
+	// ok is false for sections that must be skipped
+	ParseTier func(heading string) (tier int, ok bool)
+	// Excluded elements are dropped together with every recipe using them
+	Excluded []string
+	// SpecialSection is the heading of the section listing the special
+	// elements, which are unlocked instead of combined. They are kept as
+	// tier 0 elements marked Special.
+	SpecialSection string
+	// DeriveTiers computes the tiers from the recipes instead of trusting
+	// the headings, for pages that are not grouped by tier
+	DeriveTiers bool
+}
+
+// LittleAlchemy2 is the default dataset. Time and Ruins are unlocked by
+// the number of discovered elements rather than by a recipe, so they come
+// from the special element section.
+var LittleAlchemy2 = Source{
+	Name:           "la2",
+	URL:            DefaultURL,
+	DataPath:       DefaultDataPath,
+	ParseTier:      parseTierHeading,
+	SpecialSection: "Special element",
+}
+
+// LittleAlchemy1 is the original game. Its element list is not grouped by
+// tier, so the tiers are derived from the recipes.
+var LittleAlchemy1 = Source{
+	Name:     "la1",
+	URL:      "https://little-alchemy.fandom.com/wiki/Elements_(Little_Alchemy)",
+	DataPath: "./data/recipes_la1.json",
+	ParseTier: func(heading string) (int, bool) {
+		return 0, !strings.Contains(heading, "Special")
+	},
+	DeriveTiers: true,
+}
+
+// Sources lists the built-in sources by name
+var Sources = map[string]Source{
+	LittleAlchemy2.Name: LittleAlchemy2,
+	LittleAlchemy1.Name: LittleAlchemy1,
+}
+
+// SourceNames returns the names of the built-in sources, sorted
+func SourceNames() []string {
+	names := make([]string, 0, len(Sources))
+	for name := range Sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupSource returns the built-in source with the given name
+func LookupSource(name string) (Source, error) {
+	src, ok := Sources[name]
+	if !ok {
+		return Source{}, fmt.Errorf("unknown source %q, expected one of %s", name, strings.Join(SourceNames(), ", "))
+	}
+	return src, nil
+}
+
+// parseTierHeading reads "Tier N elements", any other heading holds the
+// base elements except the special elements section, which is read through
+// Source.SpecialSection
+func parseTierHeading(heading string) (int, bool) {
+	if heading == "Special element" {
+		return 0, false
+	}
+	tierStr := strings.TrimSuffix(strings.TrimPrefix(heading, "Tier "), " elements")
+	tier, err := strconv.Atoi(tierStr)
+	if err != nil {
+		return 0, true // Parsing gagal -> elemen dasar -> tier 0
+	}
+	return tier, true
+}
+
+func (src Source) excluded(name string) bool {
+	for _, excluded := range src.Excluded {
+		if name == excluded {
+			return true
+		}
+	}
+	return false
+}
+
+// AssignTiers sets the tier of every element to the smallest number of
+// combination rounds needed to make it: elements without any recipe are
+// base elements with tier 0, and an element made from ingredients of tier
+// a and b has tier max(a, b)+1 for its cheapest recipe. Elements that can
+// not be made from the base elements keep the highest tier plus one so
+// the cleaning drops every recipe using them.
+func AssignTiers(elements map[string]ElementData) {
+	const unknown = -1
+	tiers := make(map[string]int, len(elements))
+	for name, el := range elements {
+		tiers[name] = unknown
+		if len(el.Recipes) == 0 {
+			tiers[name] = 0
+		}
+	}
+
+	// Setiap putaran menambah satu tier, berhenti jika tidak ada perubahan
+	for round := 1; ; round++ {
+		var found []string
+		for name, el := range elements {
+			if tiers[name] != unknown {
+				continue
+			}
+			for _, recipe := range el.Recipes {
+				if madeBefore(recipe, tiers, round) {
+					found = append(found, name)
+					break
+				}
+			}
+		}
+		if len(found) == 0 {
+			break
+		}
+		for _, name := range found {
+			tiers[name] = round
+		}
+	}
+
+	maxTier := 0
+	for _, tier := range tiers {
+		maxTier = max(maxTier, tier)
+	}
+	for name, el := range elements {
+		el.Tier = tiers[name]
+		if el.Tier == unknown {
+			el.Tier = maxTier + 1
+		}
+		elements[name] = el
+	}
+}
+
+// madeBefore reports whether every ingredient has a tier below round
+func madeBefore(recipe []string, tiers map[string]int, round int) bool {
+	for _, ing := range recipe {
+		tier, ok := tiers[ing]
+		if !ok || tier < 0 || tier >= round {
+			return false
+		}
+	}
+	return true
+}
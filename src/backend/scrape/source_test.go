@@ -0,0 +1,69 @@
+package scrape
+
+import "testing"
+
+func TestAssignTiers(t *testing.T) {
+	elements := map[string]ElementData{
+		"Air":    {Tier: 5},
+		"Water":  {},
+		"Steam":  {Recipes: [][]string{{"Air", "Water"}}},
+		"Cloud":  {Recipes: [][]string{{"Air", "Steam"}}},
+		"Storm":  {Recipes: [][]string{{"Cloud", "Steam"}}},
+		"Rain":   {Recipes: [][]string{{"Cloud", "Water"}, {"Air", "Water"}}},
+		"Ghost":  {Recipes: [][]string{{"Air", "Spirit"}}},
+		"Loop":   {Recipes: [][]string{{"Loop", "Air"}}},
+		"Shadow": {Recipes: [][]string{{"Ghost", "Air"}}},
+	}
+	want := map[string]int{
+		"Air":   0,
+		"Water": 0,
+		"Steam": 1,
+		"Cloud": 2,
+		"Storm": 3,
+		// Resep termurah yang menentukan tier
+		"Rain": 1,
+		// Tidak bisa dibuat dari elemen dasar
+		"Ghost":  4,
+		"Loop":   4,
+		"Shadow": 4,
+	}
+
+	AssignTiers(elements)
+	for name, tier := range want {
+		if got := elements[name].Tier; got != tier {
+			t.Errorf("tier of %s = %d, want %d", name, got, tier)
+		}
+	}
+}
+
+func TestParseTierHeading(t *testing.T) {
+	tests := []struct {
+		heading string
+		tier    int
+		ok      bool
+	}{
+		{"Tier 1 elements", 1, true},
+		{"Tier 15 elements", 15, true},
+		{"Starting elements", 0, true},
+		{"Special element", 0, false},
+	}
+
+	for _, tt := range tests {
+		tier, ok := parseTierHeading(tt.heading)
+		if tier != tt.tier || ok != tt.ok {
+			t.Errorf("parseTierHeading(%q) = %d, %v, want %d, %v", tt.heading, tier, ok, tt.tier, tt.ok)
+		}
+	}
+}
+
+func TestLookupSource(t *testing.T) {
+	for _, name := range SourceNames() {
+		src, err := LookupSource(name)
+		if err != nil || src.Name != name {
+			t.Errorf("LookupSource(%q) = %q, %v", name, src.Name, err)
+		}
+	}
+	if _, err := LookupSource("la3"); err == nil {
+		t.Errorf("LookupSource(la3) found a source")
+	}
+}
@@ -0,0 +1,82 @@
+package search
+
+import (
+	"recipe-finder/graph"
+	"sort"
+)
+
+// Use is one recipe that takes the looked up ingredient
+type Use struct {
+	Element string   `json:"element"`
+	Recipe  []string `json:"recipe"`
+}
+
+// Reach is an element that can be made from an owned set
+type Reach struct {
+	Element string `json:"element"`
+	// Step is the round of combinations in which the element first appears
+	Step   int      `json:"step"`
+	Recipe []string `json:"recipe"`
+}
+
+// UsedIn lists every recipe that has ingredient as a direct ingredient,
+// ordered by the resulting element's name
+func UsedIn(g *graph.RecipeGraph, ingredient string) []Use {
+	var uses []Use
+	for _, element := range g.UsedIn(ingredient) {
+		for _, recipe := range g.Recipes(element) {
+			if recipe[0] == ingredient || recipe[1] == ingredient {
+				uses = append(uses, Use{Element: element, Recipe: recipe})
+			}
+		}
+	}
+	return uses
+}
+
+// Reachable returns every element that can be made from owned in at most
+// steps rounds, where each round combines any two elements obtained in
+// earlier rounds. steps <= 0 keeps going until nothing new appears. Owned
+// elements themselves are not part of the result.
+func Reachable(g *graph.RecipeGraph, owned []string, steps int) []Reach {
+	have := make(map[string]bool)
+	var frontier []string
+	for _, element := range owned {
+		if g.Has(element) && !have[element] {
+			have[element] = true
+			frontier = append(frontier, element)
+		}
+	}
+
+	var result []Reach
+	for step := 1; len(frontier) > 0 && (steps <= 0 || step <= steps); step++ {
+		var found []Reach
+		seen := make(map[string]bool)
+
+		// Hanya elemen yang memakai bahan baru dari ronde sebelumnya yang perlu dicek
+		for _, ingredient := range frontier {
+			for _, element := range g.UsedIn(ingredient) {
+				if have[element] || seen[element] {
+					continue
+				}
+				for _, recipe := range g.Recipes(element) {
+					if have[recipe[0]] && have[recipe[1]] {
+						seen[element] = true
+						found = append(found, Reach{Element: element, Step: step, Recipe: recipe})
+						break
+					}
+				}
+			}
+		}
+
+		sort.Slice(found, func(i, j int) bool {
+			return found[i].Element < found[j].Element
+		})
+		frontier = frontier[:0]
+		for _, reach := range found {
+			have[reach.Element] = true
+			frontier = append(frontier, reach.Element)
+		}
+		result = append(result, found...)
+	}
+	return result
+}
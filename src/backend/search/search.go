@@ -1,69 +1,50 @@
 package search
 
 import (
+	"context"
 	"recipe-finder/bfs"
+	"recipe-finder/bidirectional"
 	"recipe-finder/dfs"
-	"log"
+	"recipe-finder/graph"
+	"recipe-finder/model"
+	"recipe-finder/shortest"
 )
 
 // DFS
-func DFS(element string, maxRecipe int) ([]map[string][]string, float64, int) {
-	return dfs.SearchDFS(element, maxRecipe)
+func DFS(ctx context.Context, g *graph.RecipeGraph, element string, maxRecipe int, opts model.Options) model.Result {
+	return sorted(opts, dfs.SearchDFS(ctx, g, element, maxRecipe, opts))
 }
 
-// BFS
-func BFS(element string, maxRecipe int) ([]map[string][]string, float64, int) {
-	if maxRecipe > 5 {
-		initialBatch := 3
-		results, duration, nodes := bfs.SearchBFS(element, initialBatch)
-
-		if len(results) < initialBatch {
-			return results, duration, nodes
-		}
-
-		log.Printf("Found initial %d recipes, searching for %d more...", len(results), maxRecipe-len(results))
-		moreResults, moreDuration, moreNodes := bfs.SearchBFS(element, maxRecipe)
+// Bidirectional
+func Bidirectional(ctx context.Context, g *graph.RecipeGraph, element string, maxRecipe int, opts model.Options) model.Result {
+	return sorted(opts, bidirectional.SearchBidirectional(ctx, g, element, maxRecipe, opts))
+}
 
-		for _, r := range moreResults {
-			if !recipeExists(results, r) {
-				results = append(results, r)
-				if len(results) >= maxRecipe {
-					break
-				}
-			}
-		}
+// Shortest
+func Shortest(ctx context.Context, g *graph.RecipeGraph, element string, maxRecipe int, opts model.Options) model.Result {
+	return shortest.SearchShortest(ctx, g, element, maxRecipe, opts)
+}
 
-		return results, duration + moreDuration, nodes + moreNodes
+// sorted puts the recipes of a deterministic search in their stable order
+func sorted(opts model.Options, result model.Result) model.Result {
+	if opts.Deterministic {
+		model.SortRecipes(result.Recipes)
 	}
+	return result
+}
 
-	return bfs.SearchBFS(element, maxRecipe)
+// BFS
+func BFS(ctx context.Context, g *graph.RecipeGraph, element string, maxRecipe int, opts model.Options) model.Result {
+	return sorted(opts, bfs.SearchBFS(ctx, g, element, maxRecipe, opts))
 }
-func recipeExists(existing []map[string][]string, candidate map[string][]string) bool {
-	for _, r := range existing {
-		if isSameRecipe(r, candidate) {
-			return true
-		}
-	}
-	return false
+
+// BFSSession starts a BFS that can be asked for more recipes later, see
+// bfs.Session
+func BFSSession(g *graph.RecipeGraph, element string, opts model.Options) *bfs.Session {
+	return bfs.NewSession(g, element, opts)
 }
 
-func isSameRecipe(a, b map[string][]string) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for keyA, valA := range a {
-		valB, ok := b[keyA]
-		if !ok {
-			return false
-		}
-		if len(valA) != len(valB) {
-			return false
-		}
-		for i := range valA {
-			if valA[i] != valB[i] {
-				return false
-			}
-		}
-	}
-	return true
+// Next returns the next maxRecipe recipes of a BFS session
+func Next(ctx context.Context, session *bfs.Session, maxRecipe int, opts model.Options) model.Result {
+	return sorted(opts, session.Next(ctx, maxRecipe))
 }
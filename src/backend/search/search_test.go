@@ -0,0 +1,109 @@
+package search
+
+import (
+	"context"
+	"recipe-finder/graph"
+	"recipe-finder/graph/graphtest"
+	"recipe-finder/model"
+	"testing"
+)
+
+type searcher func(ctx context.Context, g *graph.RecipeGraph, element string, maxRecipe int, opts model.Options) model.Result
+
+var searchers = map[string]searcher{
+	"bfs":           BFS,
+	"dfs":           DFS,
+	"bidirectional": Bidirectional,
+	"shortest":      Shortest,
+}
+
+func TestSearchers(t *testing.T) {
+	tests := []struct {
+		element   string
+		maxRecipe int
+		want      int
+	}{
+		{"Water", 3, 1},
+		{"Lava", 3, 1},
+		{"Steam", 1, 1},
+		{"Steam", 5, 2},
+		{"Stone", 5, 2},
+		{"Cloud", 5, 2},
+		{"Rain", 4, 4},
+		{"Rain", 10, 6},
+	}
+
+	g := graphtest.Small()
+	for name, search := range searchers {
+		for _, tt := range tests {
+			result := search(context.Background(), g, tt.element, tt.maxRecipe, model.Options{Deterministic: true})
+			if len(result.Recipes) != tt.want {
+				t.Errorf("%s(%s, %d) found %d recipes, want %d", name, tt.element, tt.maxRecipe, len(result.Recipes), tt.want)
+			}
+			if result.Truncated {
+				t.Errorf("%s(%s, %d) is truncated", name, tt.element, tt.maxRecipe)
+			}
+
+			seen := make(map[string]bool)
+			for _, recipe := range result.Recipes {
+				checkRecipe(t, g, name, tt.element, recipe)
+				fingerprint := model.Fingerprint(recipe)
+				if seen[fingerprint] {
+					t.Errorf("%s(%s, %d) found %s twice", name, tt.element, tt.maxRecipe, fingerprint)
+				}
+				seen[fingerprint] = true
+			}
+		}
+	}
+}
+
+// checkRecipe verifies that recipe makes element: every element that is
+// not a base element has one of its recipes, and nothing else is in it
+func checkRecipe(t *testing.T, g *graph.RecipeGraph, name, element string, recipe map[string][]string) {
+	t.Helper()
+	if g.Tier(element) == 0 {
+		if len(recipe) != 1 || len(recipe[element]) != 0 {
+			t.Errorf("%s(%s) returned %v for a base element", name, element, recipe)
+		}
+		return
+	}
+
+	used := make(map[string]bool)
+	var visit func(string)
+	visit = func(el string) {
+		if g.Tier(el) == 0 || used[el] {
+			return
+		}
+		used[el] = true
+		ingredients, ok := recipe[el]
+		if !ok {
+			t.Errorf("%s(%s) has no recipe for %s in %v", name, element, el, recipe)
+			return
+		}
+		valid := false
+		for _, r := range g.Recipes(el) {
+			valid = valid || model.SameRecipe(r, ingredients)
+		}
+		if !valid {
+			t.Errorf("%s(%s) uses %v for %s, which is not a recipe of it", name, element, ingredients, el)
+		}
+		for _, ing := range ingredients {
+			visit(ing)
+		}
+	}
+	visit(element)
+	if len(used) != len(recipe) {
+		t.Errorf("%s(%s) returned unused recipes in %v", name, element, recipe)
+	}
+}
+
+func TestCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for name, search := range searchers {
+		result := search(ctx, graphtest.Small(), "Rain", 10, model.Options{})
+		if !result.Truncated {
+			t.Errorf("%s on a cancelled context found %d recipes without being truncated", name, len(result.Recipes))
+		}
+	}
+}
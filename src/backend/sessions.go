@@ -0,0 +1,265 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"recipe-finder/bfs"
+	"recipe-finder/graph"
+	"recipe-finder/model"
+	"recipe-finder/search"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MoreRequest asks a resumable search for a page of its recipes. Cursor is
+// the nextCursor of an earlier response, Offset picks another page of the
+// same search instead of the one after it.
+type MoreRequest struct {
+	Cursor    string `json:"cursor"`
+	Offset    *int   `json:"offset"`
+	MaxRecipe int    `json:"maxRecipe"`
+}
+
+// recipeSession is a resumable BFS together with the request that
+// started it, the graph it runs on and every recipe it found so far in
+// the order they were returned
+type recipeSession struct {
+	id      string
+	req     RecipeRequest
+	g       *graph.RecipeGraph
+	opts    model.Options
+	bfs     *bfs.Session
+	expires time.Time
+
+	// mu guards recipes and last, so pages of one session are served one
+	// at a time
+	mu      sync.Mutex
+	recipes []map[string][]string
+	// last is the result of the latest call to Next, its stats describe
+	// the whole session
+	last model.Result
+}
+
+// page returns up to limit recipes starting at offset, continuing the
+// search when the session has not found that many yet. Duration and
+// Truncated describe the work done for this page only.
+func (rs *recipeSession) page(ctx context.Context, offset, limit int) model.Result {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	result := rs.last
+	result.Duration, result.Truncated = 0, false
+	if need := offset + limit - len(rs.recipes); need > 0 && !rs.bfs.Exhausted() {
+		result = search.Next(ctx, rs.bfs, need, rs.opts)
+		rs.recipes = append(rs.recipes, result.Recipes...)
+		rs.last = result
+	}
+	start := min(offset, len(rs.recipes))
+	end := min(offset+limit, len(rs.recipes))
+	result.Recipes = rs.recipes[start:end:end]
+	return result
+}
+
+// nextOffset returns the offset of the page after one that ended at end,
+// -1 when the session has no more recipes there
+func (rs *recipeSession) nextOffset(end int) int {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if end >= len(rs.recipes) && rs.bfs.Exhausted() {
+		return -1
+	}
+	return end
+}
+
+// Cursors are "<session>.<offset>", clients should treat them as opaque
+func encodeCursor(id string, offset int) string {
+	return id + "." + strconv.Itoa(offset)
+}
+
+func decodeCursor(cursor string) (id string, offset int, ok bool) {
+	id, rest, found := strings.Cut(cursor, ".")
+	offset, err := strconv.Atoi(rest)
+	if !found || err != nil || offset < 0 {
+		return "", 0, false
+	}
+	return id, offset, true
+}
+
+// sessionStore keeps the resumable searches by id. Like cache.Cache it
+// evicts the least recently used session when full and drops sessions
+// that were not used for ttl. It is safe for concurrent use. A nil
+// *sessionStore is valid and disables resumable searches.
+type sessionStore struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	items map[string]*list.Element
+	order *list.List // front is the most recently used
+}
+
+// newSessionStore creates a store holding at most size sessions, each for
+// at most ttl after it was last used. A ttl of 0 keeps sessions until they
+// are evicted, a size below 1 returns nil.
+func newSessionStore(size int, ttl time.Duration) *sessionStore {
+	if size < 1 {
+		return nil
+	}
+	return &sessionStore{size: size, ttl: ttl, items: make(map[string]*list.Element), order: list.New()}
+}
+
+// add stores session under a new random id
+func (st *sessionStore) add(session *recipeSession) {
+	id := make([]byte, 16)
+	rand.Read(id)
+	session.id = hex.EncodeToString(id)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	session.expires = time.Now().Add(st.ttl)
+	st.items[session.id] = st.order.PushFront(session)
+	st.expire()
+	for st.order.Len() > st.size {
+		st.remove(st.order.Back())
+	}
+}
+
+// get returns the session of id and extends its lifetime
+func (st *sessionStore) get(id string) (*recipeSession, bool) {
+	if st == nil {
+		return nil, false
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.expire()
+	item, ok := st.items[id]
+	if !ok {
+		return nil, false
+	}
+	session := item.Value.(*recipeSession)
+	session.expires = time.Now().Add(st.ttl)
+	st.order.MoveToFront(item)
+	return session, true
+}
+
+// delete drops the session of id
+func (st *sessionStore) delete(id string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if item, ok := st.items[id]; ok {
+		st.remove(item)
+	}
+}
+
+// expire drops every session that was not used for ttl
+func (st *sessionStore) expire() {
+	if st.ttl <= 0 {
+		return
+	}
+	now := time.Now()
+	for item := st.order.Back(); item != nil; item = st.order.Back() {
+		if now.Before(item.Value.(*recipeSession).expires) {
+			return
+		}
+		st.remove(item)
+	}
+}
+
+// expireLoop drops expired sessions regularly so their frontier does not
+// linger while nobody asks for more
+func (st *sessionStore) expireLoop() {
+	if st == nil || st.ttl <= 0 {
+		return
+	}
+	for range time.Tick(min(st.ttl, time.Minute)) {
+		st.mu.Lock()
+		st.expire()
+		st.mu.Unlock()
+	}
+}
+
+func (st *sessionStore) remove(item *list.Element) {
+	session := st.order.Remove(item).(*recipeSession)
+	delete(st.items, session.id)
+	// Close menunggu Next yang sedang berjalan, jadi tidak dilakukan sambil memegang lock
+	go session.bfs.Close()
+}
+
+// startSession runs the first page of a resumable BFS for req. The next
+// cursor is empty when the first page already holds every recipe, the
+// session is not kept then.
+func (s *server) startSession(ctx context.Context, g *graph.RecipeGraph, req RecipeRequest) (model.Result, string) {
+	opts := s.searchOptions(req, requestOptions(req, model.Options{}))
+	session := &recipeSession{req: req, g: g, opts: opts, bfs: search.BFSSession(g, req.Element, opts)}
+	result := session.page(ctx, 0, req.MaxRecipe)
+	next := session.nextOffset(len(result.Recipes))
+	if next < 0 {
+		session.bfs.Close()
+		return result, ""
+	}
+	s.sessions.add(session)
+	return result, encodeCursor(session.id, next)
+}
+
+// handleRecipeMore returns another page of a resumable search started
+// with "resumable": true on /api/recipe
+func (s *server) handleRecipeMore(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	var more MoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&more); err != nil {
+		writeError(w, newAPIError(http.StatusBadRequest, codeInvalidRequest, "invalid request body: %v", err))
+		return
+	}
+	if more.MaxRecipe < 0 || (more.Offset != nil && *more.Offset < 0) {
+		writeError(w, newAPIError(http.StatusBadRequest, codeInvalidLimit, "maxRecipe and offset must not be negative"))
+		return
+	}
+	id, offset, ok := decodeCursor(more.Cursor)
+	var session *recipeSession
+	if ok {
+		session, ok = s.sessions.get(id)
+	}
+	if !ok {
+		writeError(w, newAPIError(http.StatusNotFound, codeUnknownCursor, "unknown or expired cursor %q", more.Cursor))
+		return
+	}
+	if more.Offset != nil {
+		offset = *more.Offset
+	}
+	// Tanpa maxRecipe ukuran halaman sama dengan request pertama
+	req := session.req
+	if more.MaxRecipe > 0 {
+		req.MaxRecipe = more.MaxRecipe
+	}
+
+	ctx, cancel := s.searchContext(r)
+	defer cancel()
+
+	result := session.page(ctx, offset, req.MaxRecipe)
+	if err := timeoutError(ctx, req, result); err != nil {
+		writeError(w, err)
+		return
+	}
+	response := recipeResponse(session.g, req, result)
+	response.Offset = &offset
+	if next := session.nextOffset(offset + len(result.Recipes)); next >= 0 {
+		response.NextCursor = encodeCursor(session.id, next)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"recipe-finder/graph/graphtest"
+	"recipe-finder/model"
+	"recipe-finder/search"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	tests := []struct {
+		cursor string
+		id     string
+		offset int
+		ok     bool
+	}{
+		{encodeCursor("0a1b", 0), "0a1b", 0, true},
+		{encodeCursor("0a1b", 25), "0a1b", 25, true},
+		{"0a1b.7", "0a1b", 7, true},
+		{"0a1b", "", 0, false},
+		{"0a1b.", "", 0, false},
+		{"0a1b.-1", "", 0, false},
+		{"0a1b.x", "", 0, false},
+		{"0a1b.1.2", "", 0, false},
+		{"", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		id, offset, ok := decodeCursor(tt.cursor)
+		if id != tt.id || offset != tt.offset || ok != tt.ok {
+			t.Errorf("decodeCursor(%q) = %q, %d, %v, want %q, %d, %v", tt.cursor, id, offset, ok, tt.id, tt.offset, tt.ok)
+		}
+	}
+}
+
+func TestRecipeSessionPage(t *testing.T) {
+	g := graphtest.Small()
+	opts := model.Options{Deterministic: true}
+	session := &recipeSession{g: g, opts: opts, bfs: search.BFSSession(g, "Rain", opts)}
+	defer session.bfs.Close()
+
+	tests := []struct {
+		offset, limit int
+		want          int
+		next          int
+	}{
+		{0, 2, 2, 2},
+		{2, 2, 2, 4},
+		// Halaman yang sudah pernah diambil dibaca dari hasil yang tersimpan
+		{0, 3, 3, 3},
+		{4, 4, 2, -1},
+		{6, 2, 0, -1},
+		{10, 2, 0, -1},
+		{1, 10, 5, -1},
+	}
+
+	var pages [][]map[string][]string
+	for _, tt := range tests {
+		result := session.page(context.Background(), tt.offset, tt.limit)
+		if len(result.Recipes) != tt.want {
+			t.Errorf("page(%d, %d) returned %d recipes, want %d", tt.offset, tt.limit, len(result.Recipes), tt.want)
+		}
+		if next := session.nextOffset(tt.offset + len(result.Recipes)); next != tt.next {
+			t.Errorf("nextOffset after page(%d, %d) = %d, want %d", tt.offset, tt.limit, next, tt.next)
+		}
+		if result.Truncated {
+			t.Errorf("page(%d, %d) is truncated", tt.offset, tt.limit)
+		}
+		pages = append(pages, result.Recipes)
+	}
+
+	// Setiap offset selalu mengembalikan resep yang sama
+	all := session.page(context.Background(), 0, 10).Recipes
+	seen := make(map[string]bool)
+	for _, recipe := range all {
+		seen[model.Fingerprint(recipe)] = true
+	}
+	if len(all) != 6 || len(seen) != 6 {
+		t.Fatalf("session found %d recipes, %d unique, want 6", len(all), len(seen))
+	}
+	for i, tt := range tests {
+		for j, recipe := range pages[i] {
+			if model.Fingerprint(recipe) != model.Fingerprint(all[tt.offset+j]) {
+				t.Errorf("page(%d, %d) recipe %d differs from the same offset in the whole session", tt.offset, tt.limit, j)
+			}
+		}
+	}
+}
+
+// postMore sends more to /api/recipe/more and decodes the response into v
+func postMore(t *testing.T, s *server, more MoreRequest, v any) int {
+	t.Helper()
+	body, _ := json.Marshal(more)
+	rec := httptest.NewRecorder()
+	s.handleRecipeMore(rec, httptest.NewRequest(http.MethodPost, "/api/recipe/more", bytes.NewReader(body)))
+	if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+		t.Fatalf("decoding response of %s: %v", more.Cursor, err)
+	}
+	return rec.Code
+}
+
+// moreResponse is a RecipeResponse or the error of a failed request
+type moreResponse struct {
+	RecipeResponse
+	Error apiError `json:"error"`
+}
+
+func TestHandleRecipeMore(t *testing.T) {
+	s := newSmallServer()
+	s.sessions = newSessionStore(2, time.Minute)
+	req := RecipeRequest{Element: "Rain", Algorithm: "bfs", MaxRecipe: 2, Deterministic: true, Resumable: true}
+
+	var first moreResponse
+	if code := postRecipe(t, s, req, &first); code != http.StatusOK || first.NextCursor == "" || first.Offset == nil || *first.Offset != 0 {
+		t.Fatalf("resumable search answered %d with cursor %q", code, first.NextCursor)
+	}
+	all := decodeRecipes(first.Results)
+
+	// Halaman berikutnya melanjutkan dari cursor sampai resepnya habis
+	cursor := first.NextCursor
+	for _, want := range []struct{ offset, recipes int }{{2, 2}, {4, 2}} {
+		var page moreResponse
+		if code := postMore(t, s, MoreRequest{Cursor: cursor}, &page); code != http.StatusOK {
+			t.Fatalf("page at %d answered %d: %s", want.offset, code, page.Error.Message)
+		}
+		recipes := decodeRecipes(page.Results)
+		if *page.Offset != want.offset || len(recipes) != want.recipes {
+			t.Errorf("page at offset %d with %d recipes, want %d with %d", *page.Offset, len(recipes), want.offset, want.recipes)
+		}
+		all = append(all, recipes...)
+		cursor = page.NextCursor
+	}
+	if cursor != "" || len(all) != 6 {
+		t.Errorf("paged %d recipes, last cursor %q; want 6 and no cursor", len(all), cursor)
+	}
+
+	// offset dan maxRecipe memilih halaman lain dari sesi yang sama
+	offset := 0
+	var whole moreResponse
+	postMore(t, s, MoreRequest{Cursor: first.NextCursor, Offset: &offset, MaxRecipe: 6}, &whole)
+	if got := decodeRecipes(whole.Results); !reflect.DeepEqual(got, all) {
+		t.Errorf("offset 0 with maxRecipe 6 = %v, want the pages %v", got, all)
+	}
+
+	negative := -1
+	tests := []struct {
+		name   string
+		more   MoreRequest
+		status int
+		code   string
+	}{
+		{"unknown", MoreRequest{Cursor: encodeCursor("0a1b", 2)}, http.StatusNotFound, codeUnknownCursor},
+		{"malformed", MoreRequest{Cursor: "0a1b"}, http.StatusNotFound, codeUnknownCursor},
+		{"negative offset", MoreRequest{Cursor: first.NextCursor, Offset: &negative}, http.StatusBadRequest, codeInvalidLimit},
+		{"negative limit", MoreRequest{Cursor: first.NextCursor, MaxRecipe: -1}, http.StatusBadRequest, codeInvalidLimit},
+	}
+	for _, tt := range tests {
+		var resp moreResponse
+		if code := postMore(t, s, tt.more, &resp); code != tt.status || resp.Error.Code != tt.code {
+			t.Errorf("%s: %d %s, want %d %s", tt.name, code, resp.Error.Code, tt.status, tt.code)
+		}
+	}
+
+	// Sesi terlama dibuang begitu lebih dari dua sesi disimpan
+	for i := 0; i < 2; i++ {
+		var resp moreResponse
+		postRecipe(t, s, req, &resp)
+	}
+	var evicted moreResponse
+	if code := postMore(t, s, MoreRequest{Cursor: first.NextCursor}, &evicted); code != http.StatusNotFound {
+		t.Errorf("evicted session answered %d", code)
+	}
+}
+
+func TestRecipeResumable(t *testing.T) {
+	s := newSmallServer()
+	s.sessions = newSessionStore(2, time.Minute)
+	tests := []struct {
+		name   string
+		req    RecipeRequest
+		status int
+		cursor bool
+	}{
+		{"paged", RecipeRequest{Element: "Rain", Algorithm: "bfs", MaxRecipe: 2, Resumable: true}, http.StatusOK, true},
+		// Halaman pertama sudah berisi semua resep, sesinya tidak disimpan
+		{"complete", RecipeRequest{Element: "Rain", Algorithm: "bfs", MaxRecipe: 10, Resumable: true}, http.StatusOK, false},
+		{"dfs", RecipeRequest{Element: "Rain", Algorithm: "dfs", MaxRecipe: 2, Resumable: true}, http.StatusBadRequest, false},
+		{"shortest", RecipeRequest{Element: "Rain", Algorithm: "bfs", Mode: "shortest", MaxRecipe: 2, Resumable: true}, http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		var resp moreResponse
+		if code := postRecipe(t, s, tt.req, &resp); code != tt.status || (resp.NextCursor != "") != tt.cursor {
+			t.Errorf("%s: %d with cursor %q, want %d", tt.name, code, resp.NextCursor, tt.status)
+		}
+	}
+
+	s.sessions = nil
+	var resp moreResponse
+	if code := postRecipe(t, s, tests[0].req, &resp); code != http.StatusBadRequest {
+		t.Errorf("resumable search without a session store answered %d", code)
+	}
+	if code := postMore(t, s, MoreRequest{Cursor: encodeCursor("0a1b", 2)}, &resp); code != http.StatusNotFound {
+		t.Errorf("more without a session store answered %d", code)
+	}
+}
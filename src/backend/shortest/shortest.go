@@ -0,0 +1,277 @@
+package shortest
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"recipe-finder/graph"
+	"recipe-finder/model"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxFrontier caps the partial recipe maps waiting at once. Past it the
+// search stops with Truncated set instead of growing without bound.
+const maxFrontier = 1 << 20
+
+// unreachable is the bound of an element that cannot be made
+const unreachable = -1
+
+// binding records the recipe chosen for one element of a partial map, the
+// chosen recipes form a list shared with the state it was expanded from
+type binding struct {
+	parent  *binding
+	element string
+	recipe  []string
+}
+
+// state is a partial recipe map. steps is its number of entries, pending
+// holds the elements that still need a recipe in pending order.
+type state struct {
+	recipes *binding
+	pending []string
+	steps   int
+	// bound is steps plus a lower bound of the entries still needed
+	bound int
+	seq   int
+}
+
+type solver struct {
+	ctx  context.Context
+	g    *graph.RecipeGraph
+	opts model.Options
+	// bounds memoizes the lower bound of every element seen so far
+	bounds map[string]int
+	// expanded counts how often the states missing the same elements were
+	// expanded, see SearchShortest
+	expanded map[string]int
+	seq      int
+	metrics  model.Metrics
+}
+
+// SearchShortest returns up to maxRecipe recipe maps ordered by the number of
+// combinations in them, the first one being minimal. An element used by
+// several ingredients is combined once and counted once, as in the map.
+//
+// Partial maps are expanded best first, ranked by their entries plus a
+// lower bound of the entries their missing elements need. The bound never
+// overestimates and drops by at most one per entry, so complete maps come
+// out cheapest first. Elements get their recipe highest tier first, which
+// makes the ways to finish a partial map depend only on the elements it
+// is missing: once maxRecipe partial maps missing the same elements were
+// expanded, the costlier ones can not lead to any of the first maxRecipe
+// results and are dropped.
+func SearchShortest(ctx context.Context, g *graph.RecipeGraph, element string, maxRecipe int, opts model.Options) model.Result {
+	element = strings.TrimSpace(element)
+	log.Println("Starting shortest search for element:", element)
+	startTime := time.Now()
+
+	if !g.Has(element) {
+		duration := time.Since(startTime)
+		log.Println("Element not found in recipe graph")
+		log.Printf("Shortest search took %s", duration)
+		return model.Result{Duration: duration.Seconds()}
+	}
+
+	if opts.IsLeaf(g, element) || len(g.Recipes(element)) == 0 {
+		recipeMap := map[string][]string{element: {}}
+		if opts.OnRecipe != nil {
+			opts.OnRecipe(recipeMap)
+		}
+		duration := time.Since(startTime)
+		log.Printf("Shortest search took %s", duration)
+		return model.Result{
+			Recipes:     []map[string][]string{recipeMap},
+			Duration:    duration.Seconds(),
+			VisitedNode: 1,
+			Steps:       []int{0},
+		}
+	}
+
+	s := &solver{
+		ctx:      ctx,
+		g:        g,
+		opts:     opts,
+		bounds:   make(map[string]int),
+		expanded: make(map[string]int),
+	}
+
+	var result []map[string][]string
+	var steps []int
+	stopped := false
+	queue := &stateQueue{}
+	if s.bound(element) != unreachable {
+		s.push(queue, &state{pending: []string{element}})
+	}
+	for len(result) < maxRecipe && queue.Len() > 0 {
+		if ctx.Err() != nil || queue.Len() > maxFrontier {
+			stopped = true
+			break
+		}
+		current := heap.Pop(queue).(*state)
+		if len(current.pending) == 0 {
+			recipeMap := make(map[string][]string, current.steps)
+			for b := current.recipes; b != nil; b = b.parent {
+				recipeMap[b.element] = b.recipe
+			}
+			result = append(result, recipeMap)
+			steps = append(steps, current.steps)
+			if opts.OnRecipe != nil {
+				opts.OnRecipe(recipeMap)
+			}
+			continue
+		}
+
+		key := strings.Join(current.pending, "\x00")
+		if s.expanded[key] >= maxRecipe {
+			continue
+		}
+		s.expanded[key]++
+		s.expand(queue, current)
+	}
+
+	truncated := stopped && len(result) < maxRecipe
+	if truncated && ctx.Err() != nil {
+		log.Printf("Shortest search stopped early: %v", ctx.Err())
+	} else if truncated {
+		log.Printf("Shortest search stopped with %d partial recipes waiting", queue.Len())
+	}
+
+	duration := time.Since(startTime)
+	log.Printf("Shortest search took %s", duration)
+
+	stats := s.metrics.Stats()
+	return model.Result{
+		Recipes:     result,
+		Duration:    duration.Seconds(),
+		VisitedNode: stats.VisitedNodes(),
+		Truncated:   truncated,
+		Steps:       steps,
+		Stats:       stats,
+	}
+}
+
+// expand chooses every usable recipe for the first pending element of
+// current. Ingredients that are pending already are shared, so each
+// element is combined once.
+func (s *solver) expand(queue *stateQueue, current *state) {
+	s.metrics.Expand()
+	element := current.pending[0]
+
+	for _, recipe := range s.opts.Recipes(s.g, element) {
+		s.metrics.Consider()
+		if !s.canUse(element, recipe) {
+			continue
+		}
+
+		pending := current.pending[1:]
+		for _, ing := range recipe {
+			if !s.opts.IsLeaf(s.g, ing) {
+				pending = s.insert(pending, ing)
+			}
+		}
+		s.push(queue, &state{
+			recipes: &binding{parent: current.recipes, element: element, recipe: recipe},
+			pending: pending,
+			steps:   current.steps + 1,
+		})
+	}
+}
+
+// push ranks a state and adds it to the queue
+func (s *solver) push(queue *stateQueue, st *state) {
+	remaining := len(st.pending)
+	for _, element := range st.pending {
+		remaining = max(remaining, s.bound(element))
+	}
+	st.bound = st.steps + remaining
+	st.seq = s.seq
+	s.seq++
+	heap.Push(queue, st)
+	s.metrics.Generate(queue.Len())
+}
+
+// bound returns the fewest entries any recipe map of element can have:
+// its own entry on top of its costlier ingredient, for the cheapest
+// recipe. It is unreachable when element cannot be made at all.
+func (s *solver) bound(element string) int {
+	if s.opts.IsLeaf(s.g, element) {
+		return 0
+	}
+	if b, ok := s.bounds[element]; ok {
+		return b
+	}
+
+	best := unreachable
+	tier := s.g.Tier(element)
+	for _, recipe := range s.opts.Recipes(s.g, element) {
+		if s.g.Tier(recipe[0]) >= tier || s.g.Tier(recipe[1]) >= tier {
+			continue
+		}
+		left, right := s.bound(recipe[0]), s.bound(recipe[1])
+		if left == unreachable || right == unreachable {
+			continue
+		}
+		if cost := 1 + max(left, right); best == unreachable || cost < best {
+			best = cost
+		}
+	}
+	s.bounds[element] = best
+	return best
+}
+
+// canUse reports whether recipe can make element: both ingredients come
+// from a lower tier and can be made themselves
+func (s *solver) canUse(element string, recipe []string) bool {
+	tier := s.g.Tier(element)
+	for _, ing := range recipe {
+		if s.g.Tier(ing) >= tier || s.bound(ing) == unreachable {
+			return false
+		}
+	}
+	return true
+}
+
+// insert returns pending with element added in pending order: highest
+// tier first, then by name. An element is given its recipe only after
+// every pending element that could use it, and is never added twice.
+// pending itself is not modified.
+func (s *solver) insert(pending []string, element string) []string {
+	tier := s.g.Tier(element)
+	i := sort.Search(len(pending), func(i int) bool {
+		t := s.g.Tier(pending[i])
+		return t < tier || (t == tier && pending[i] >= element)
+	})
+	if i < len(pending) && pending[i] == element {
+		return pending
+	}
+	inserted := make([]string, 0, len(pending)+1)
+	inserted = append(inserted, pending[:i]...)
+	inserted = append(inserted, element)
+	return append(inserted, pending[i:]...)
+}
+
+// stateQueue is a min-heap of partial recipe maps ordered by bound
+type stateQueue []*state
+
+func (q stateQueue) Len() int { return len(q) }
+func (q stateQueue) Less(a, b int) bool {
+	if q[a].bound != q[b].bound {
+		return q[a].bound < q[b].bound
+	}
+	// Map yang lebih lengkap diambil dulu, lalu urutan masuk agar hasilnya tetap
+	if q[a].steps != q[b].steps {
+		return q[a].steps > q[b].steps
+	}
+	return q[a].seq < q[b].seq
+}
+func (q stateQueue) Swap(a, b int) { q[a], q[b] = q[b], q[a] }
+func (q *stateQueue) Push(x any)   { *q = append(*q, x.(*state)) }
+func (q *stateQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,82 @@
+package shortest
+
+import (
+	"context"
+	"recipe-finder/graph"
+	"recipe-finder/graph/graphtest"
+	"recipe-finder/model"
+	"testing"
+)
+
+// sharedGraph has two ways to make Golem. Through Clay and Brick both use
+// Sand, which is made once: 5 combinations in a tree of 7. Through Statue
+// it is a chain of 6 combinations without any sharing.
+func sharedGraph() *graph.RecipeGraph {
+	return graph.New(map[string]graph.Recipe{
+		"Air":    {Tier: 0},
+		"Earth":  {Tier: 0},
+		"Fire":   {Tier: 0},
+		"Water":  {Tier: 0},
+		"Dust":   {Tier: 1, Recipes: [][]string{{"Earth", "Water"}}},
+		"Sand":   {Tier: 2, Recipes: [][]string{{"Air", "Dust"}}},
+		"Clay":   {Tier: 3, Recipes: [][]string{{"Sand", "Water"}}},
+		"Brick":  {Tier: 3, Recipes: [][]string{{"Fire", "Sand"}}},
+		"Metal":  {Tier: 1, Recipes: [][]string{{"Earth", "Fire"}}},
+		"Wire":   {Tier: 2, Recipes: [][]string{{"Air", "Metal"}}},
+		"Tool":   {Tier: 3, Recipes: [][]string{{"Fire", "Wire"}}},
+		"Chisel": {Tier: 4, Recipes: [][]string{{"Earth", "Tool"}}},
+		"Statue": {Tier: 5, Recipes: [][]string{{"Chisel", "Water"}}},
+		"Golem":  {Tier: 6, Recipes: [][]string{{"Brick", "Clay"}, {"Air", "Statue"}}},
+	})
+}
+
+func TestSearchShortest(t *testing.T) {
+	tests := []struct {
+		name      string
+		g         *graph.RecipeGraph
+		element   string
+		maxRecipe int
+		steps     []int
+		first     string
+	}{
+		// Sand dipakai dua kali tetapi hanya dihitung sekali
+		{"shared", sharedGraph(), "Golem", 5, []int{5, 6}, "Clay"},
+		{"shared first", sharedGraph(), "Golem", 1, []int{5}, "Clay"},
+		{"small", graphtest.Small(), "Rain", 10, []int{3, 3, 4, 4, 5, 5}, "Cloud"},
+		{"small limit", graphtest.Small(), "Rain", 3, []int{3, 3, 4}, "Cloud"},
+		{"base", graphtest.Small(), "Water", 3, []int{0}, ""},
+	}
+
+	for _, tt := range tests {
+		result := SearchShortest(context.Background(), tt.g, tt.element, tt.maxRecipe, model.Options{})
+		if len(result.Steps) != len(tt.steps) || len(result.Recipes) != len(tt.steps) {
+			t.Errorf("%s: steps = %v for %d recipes, want %v", tt.name, result.Steps, len(result.Recipes), tt.steps)
+			continue
+		}
+		for i, recipe := range result.Recipes {
+			if result.Steps[i] != tt.steps[i] {
+				t.Errorf("%s: steps = %v, want %v", tt.name, result.Steps, tt.steps)
+				break
+			}
+			// Steps adalah jumlah entri peta resep, kecuali untuk elemen dasar
+			if tt.steps[i] > 0 && len(recipe) != tt.steps[i] {
+				t.Errorf("%s: recipe %d has %d combinations but %d steps", tt.name, i, len(recipe), tt.steps[i])
+			}
+		}
+		if _, ok := result.Recipes[0][tt.first]; tt.first != "" && !ok {
+			t.Errorf("%s: first recipe %v does not use %s", tt.name, result.Recipes[0], tt.first)
+		}
+		if result.Truncated {
+			t.Errorf("%s: result is truncated", tt.name)
+		}
+	}
+}
+
+func TestSearchShortestCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := SearchShortest(ctx, sharedGraph(), "Golem", 5, model.Options{})
+	if !result.Truncated || len(result.Recipes) != 0 {
+		t.Errorf("cancelled search found %d recipes, truncated %v", len(result.Recipes), result.Truncated)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"recipe-finder/model"
+	"strconv"
+	"strings"
+)
+
+// streamDone is the last event of a stream
+type streamDone struct {
+	Duration    float64 `json:"duration"`
+	VisitedNode int     `json:"visitedNode"`
+	Truncated   bool    `json:"truncated"`
+	Steps       []int   `json:"steps,omitempty"`
+	Cache       string  `json:"cache,omitempty"`
+	// Special is RecipeResponse.Special for every recipe sent
+	Special map[string]string `json:"special,omitempty"`
+	Budget  string            `json:"budget,omitempty"`
+	Pruned  int               `json:"pruned,omitempty"`
+	Stats   model.Stats       `json:"stats"`
+}
+
+// recipeRequestFromQuery reads a RecipeRequest from the URL query, used by
+// the GET endpoints since EventSource and WebSocket cannot send a body
+func recipeRequestFromQuery(r *http.Request) (RecipeRequest, *apiError) {
+	q := r.URL.Query()
+	req := RecipeRequest{
+		Element:   q.Get("element"),
+		Algorithm: q.Get("algorithm"),
+		Mode:      q.Get("mode"),
+		Format:    q.Get("format"),
+		Dataset:   q.Get("dataset"),
+	}
+	if raw := q.Get("maxRecipe"); raw != "" {
+		maxRecipe, err := strconv.Atoi(raw)
+		if err != nil {
+			return req, newAPIError(http.StatusBadRequest, codeInvalidLimit, "invalid maxRecipe %q", raw)
+		}
+		req.MaxRecipe = maxRecipe
+	}
+	for key, limit := range map[string]*int{"maxNodes": &req.MaxNodes, "maxMemoryMB": &req.MaxMemoryMB} {
+		if raw := q.Get(key); raw != "" {
+			value, err := strconv.Atoi(raw)
+			if err != nil {
+				return req, newAPIError(http.StatusBadRequest, codeInvalidLimit, "invalid %s %q", key, raw)
+			}
+			*limit = value
+		}
+	}
+	if raw := q.Get("deterministic"); raw != "" {
+		deterministic, err := strconv.ParseBool(raw)
+		if err != nil {
+			return req, newAPIError(http.StatusBadRequest, codeInvalidRequest, "invalid deterministic %q", raw)
+		}
+		req.Deterministic = deterministic
+	}
+	if raw := q.Get("includeSpecial"); raw != "" {
+		includeSpecial, err := strconv.ParseBool(raw)
+		if err != nil {
+			return req, newAPIError(http.StatusBadRequest, codeInvalidRequest, "invalid includeSpecial %q", raw)
+		}
+		req.IncludeSpecial = includeSpecial
+	}
+	// owned boleh diulang atau dipisah koma: owned=Clay&owned=Stone,Sand
+	for _, raw := range q["owned"] {
+		for _, name := range strings.Split(raw, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				req.Owned = append(req.Owned, name)
+			}
+		}
+	}
+	return req, nil
+}
+
+// writeEvent writes a single Server-Sent Event and flushes it to the client
+func writeEvent(w http.ResponseWriter, flusher http.Flusher, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
+// handleRecipeStream sends every recipe as a "recipe" event as soon as the
+// search finds it, followed by one "done" event with the search statistics.
+// A deterministic search sends its recipes in their sorted order once it is
+// done, see holdsRecipes. A search that times out without any recipe sends
+// an "error" event first.
+func (s *server) handleRecipeStream(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	req, err := recipeRequestFromQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	g, apiErr := s.graphFor(&req.Dataset)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	if err := validateRequest(g, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, newAPIError(http.StatusInternalServerError, codeInvalidRequest, "streaming unsupported"))
+		return
+	}
+
+	ctx, cancel := s.searchContext(r)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	recipes := make(chan map[string][]string, 16)
+	finished := make(chan model.Result, 1)
+	var cacheStatus string
+	var opts model.Options
+	if !holdsRecipes(req) {
+		opts.OnRecipe = func(recipe map[string][]string) {
+			select {
+			case recipes <- recipe:
+			case <-ctx.Done():
+			}
+		}
+	}
+	go func() {
+		result, status := s.explore(ctx, g, req, opts)
+		// Ditulis sebelum dikirim ke finished sehingga aman dibaca setelahnya
+		cacheStatus = status
+		finished <- result
+	}()
+
+	for {
+		select {
+		case recipe := <-recipes:
+			if err := writeEvent(w, flusher, "recipe", formatRecipe(g, req, recipe)); err != nil {
+				cancel()
+			}
+		case result := <-finished:
+			// Every send happened before the search returned, drain what is left
+			for len(recipes) > 0 {
+				writeEvent(w, flusher, "recipe", formatRecipe(g, req, <-recipes))
+			}
+			if holdsRecipes(req) {
+				for _, recipe := range result.Recipes {
+					writeEvent(w, flusher, "recipe", formatRecipe(g, req, recipe))
+				}
+			}
+			if err := timeoutError(ctx, req, result); err != nil {
+				writeEvent(w, flusher, "error", err)
+			}
+			writeEvent(w, flusher, "done", streamDone{
+				Duration:    result.Duration,
+				VisitedNode: result.VisitedNode,
+				Truncated:   result.Truncated,
+				Steps:       result.Steps,
+				Cache:       cacheStatus,
+				Special:     specialElements(g, result.Recipes),
+				Budget:      result.Budget,
+				Pruned:      result.Pruned,
+				Stats:       result.Stats,
+			})
+			return
+		}
+	}
+}
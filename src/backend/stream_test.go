@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sseEvent is one Server-Sent Event with its raw JSON data
+type sseEvent struct {
+	name string
+	data string
+}
+
+// getStream requests /api/recipe/stream with query and returns the
+// response together with every event sent
+func getStream(s *server, query url.Values) (*httptest.ResponseRecorder, []sseEvent) {
+	rec := httptest.NewRecorder()
+	s.handleRecipeStream(rec, httptest.NewRequest(http.MethodGet, "/api/recipe/stream?"+query.Encode(), nil))
+
+	var events []sseEvent
+	var current sseEvent
+	scanner := bufio.NewScanner(bytes.NewReader(rec.Body.Bytes()))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		case line == "" && current.name != "":
+			events = append(events, current)
+			current = sseEvent{}
+		}
+	}
+	return rec, events
+}
+
+func TestRecipeStreamDeterministicOrder(t *testing.T) {
+	s := newSmallServer()
+	for _, algorithm := range []string{"bfs", "dfs", "bidirectional"} {
+		req := RecipeRequest{Element: "Rain", Algorithm: algorithm, MaxRecipe: 10, Deterministic: true}
+		var want RecipeResponse
+		postRecipe(t, s, req, &want)
+
+		query := url.Values{"element": {"Rain"}, "algorithm": {algorithm}, "maxRecipe": {"10"}, "deterministic": {"true"}}
+		rec, events := getStream(s, query)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", algorithm, rec.Code)
+		}
+		var got []map[string][]string
+		for _, event := range events {
+			if event.name != "recipe" {
+				continue
+			}
+			var recipe map[string][]string
+			json.Unmarshal([]byte(event.data), &recipe)
+			got = append(got, recipe)
+		}
+		// Urutan stream harus sama dengan urutan response biasa yang sudah diurutkan
+		if !reflect.DeepEqual(got, decodeRecipes(want.Results)) {
+			t.Errorf("%s: streamed %v, want %v", algorithm, got, want.Results)
+		}
+		if last := events[len(events)-1]; last.name != "done" {
+			t.Errorf("%s: last event is %q, want done", algorithm, last.name)
+		}
+	}
+}
+
+// decodeRecipes converts the flat results of a decoded response back to
+// recipe maps
+func decodeRecipes(results any) []map[string][]string {
+	raw, _ := json.Marshal(results)
+	var recipes []map[string][]string
+	json.Unmarshal(raw, &recipes)
+	return recipes
+}
+
+func TestRecipeStream(t *testing.T) {
+	s := newSmallServer()
+	tests := []struct {
+		name    string
+		query   url.Values
+		status  int
+		recipes int
+		code    string
+	}{
+		{"bfs", url.Values{"element": {"Rain"}, "algorithm": {"bfs"}, "maxRecipe": {"4"}}, http.StatusOK, 4, ""},
+		{"all", url.Values{"element": {"Rain"}, "algorithm": {"dfs"}, "maxRecipe": {"10"}}, http.StatusOK, 6, ""},
+		{"tree", url.Values{"element": {"Stone"}, "algorithm": {"bfs"}, "maxRecipe": {"5"}, "format": {"tree"}}, http.StatusOK, 2, ""},
+		{"shortest", url.Values{"element": {"Rain"}, "mode": {"shortest"}, "maxRecipe": {"3"}}, http.StatusOK, 3, ""},
+		// Kesalahan request dijawab sebagai JSON biasa sebelum stream dimulai
+		{"unknown element", url.Values{"element": {"Rian"}, "algorithm": {"bfs"}, "maxRecipe": {"1"}}, http.StatusNotFound, 0, codeUnknownElement},
+		{"bad limit", url.Values{"element": {"Rain"}, "algorithm": {"bfs"}, "maxRecipe": {"x"}}, http.StatusBadRequest, 0, codeInvalidLimit},
+		{"bad algorithm", url.Values{"element": {"Rain"}, "algorithm": {"astar"}, "maxRecipe": {"1"}}, http.StatusBadRequest, 0, codeUnknownAlgorithm},
+	}
+
+	for _, tt := range tests {
+		rec, events := getStream(s, tt.query)
+		if rec.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, rec.Code, tt.status)
+			continue
+		}
+		if tt.code != "" {
+			var body struct{ Error apiError }
+			json.NewDecoder(rec.Body).Decode(&body)
+			if body.Error.Code != tt.code {
+				t.Errorf("%s: error code %q, want %q", tt.name, body.Error.Code, tt.code)
+			}
+			continue
+		}
+		if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+			t.Errorf("%s: Content-Type %q", tt.name, got)
+		}
+
+		recipes := 0
+		for _, event := range events[:len(events)-1] {
+			if event.name != "recipe" {
+				t.Errorf("%s: unexpected %s event before done", tt.name, event.name)
+			}
+			recipes++
+		}
+		last := events[len(events)-1]
+		var done streamDone
+		if err := json.Unmarshal([]byte(last.data), &done); last.name != "done" || err != nil {
+			t.Errorf("%s: last event %s %s, want done", tt.name, last.name, last.data)
+			continue
+		}
+		if recipes != tt.recipes {
+			t.Errorf("%s: %d recipe events, want %d", tt.name, recipes, tt.recipes)
+		}
+		if done.Stats.Expanded == 0 {
+			t.Errorf("%s: done event has no stats", tt.name)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	s.handleRecipeStream(rec, httptest.NewRequest(http.MethodPost, "/api/recipe/stream", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodGet {
+		t.Errorf("POST answered %d with Allow %q", rec.Code, rec.Header().Get("Allow"))
+	}
+}
+
+func TestRecipeRequestFromQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  RecipeRequest
+		code  string
+	}{
+		{"element=Rain&algorithm=dfs&maxRecipe=3", RecipeRequest{Element: "Rain", Algorithm: "dfs", MaxRecipe: 3}, ""},
+		{"element=Rain&mode=shortest&format=tree&dataset=la1", RecipeRequest{Element: "Rain", Mode: "shortest", Format: "tree", Dataset: "la1"}, ""},
+		{"element=Rain&owned=Stone,Cloud&owned=%20Steam%20", RecipeRequest{Element: "Rain", Owned: []string{"Stone", "Cloud", "Steam"}}, ""},
+		{"element=Rain&deterministic=true&includeSpecial=1&maxNodes=10&maxMemoryMB=5", RecipeRequest{Element: "Rain", Deterministic: true, IncludeSpecial: true, MaxNodes: 10, MaxMemoryMB: 5}, ""},
+		{"maxRecipe=many", RecipeRequest{}, codeInvalidLimit},
+		{"maxNodes=-", RecipeRequest{}, codeInvalidLimit},
+		{"deterministic=maybe", RecipeRequest{}, codeInvalidRequest},
+		{"includeSpecial=yes", RecipeRequest{}, codeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		got, err := recipeRequestFromQuery(httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil))
+		if tt.code != "" {
+			if err == nil || err.Code != tt.code {
+				t.Errorf("%s: error %v, want %s", tt.query, err, tt.code)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, %v, want %+v", tt.query, got, err, tt.want)
+		}
+	}
+}
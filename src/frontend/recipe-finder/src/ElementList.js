@@ -1,16 +1,35 @@
 import React, { useEffect, useState } from 'react';
 
 const PAGE_SIZE = 32;
+const FETCH_LIMIT = 1000;
 
 export default function ElementList({ onSelect, search }) {
   const [elements, setElements] = useState([]);
   const [currentPage, setCurrentPage] = useState(1);
 
   useEffect(() => {
-    fetch('/elements.json')
-      .then((res) => res.json())
-      .then((data) => setElements(data))
-      .catch((err) => console.error('Failed to load elements:', err));
+    let cancelled = false;
+
+    // Backend membatasi satu halaman maksimal 1000 elemen, jadi ambil per halaman sampai total
+    async function loadElements() {
+      const loaded = [];
+      let total = Infinity;
+      while (loaded.length < total) {
+        const res = await fetch(
+          `https://backend-recipe-production.up.railway.app/api/elements?limit=${FETCH_LIMIT}&offset=${loaded.length}`
+        );
+        const data = await res.json();
+        if (data.elements.length === 0) break;
+        loaded.push(...data.elements.map((el) => ({ Name: el.name, ImageURL: el.imageURL })));
+        total = data.total;
+      }
+      if (!cancelled) setElements(loaded);
+    }
+
+    loadElements().catch((err) => console.error('Failed to load elements:', err));
+    return () => {
+      cancelled = true;
+    };
   }, []);
   useEffect(() => {
     setCurrentPage(1); 